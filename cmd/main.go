@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"vocabulary-app-be/internal/auth"
+	"vocabulary-app-be/internal/deck"
+	"vocabulary-app-be/internal/quiz"
 	"vocabulary-app-be/internal/vocab"
 	"vocabulary-app-be/pkg/config"
 	"vocabulary-app-be/pkg/database"
+	"vocabulary-app-be/pkg/mailer"
 	"vocabulary-app-be/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -31,17 +36,47 @@ func main() {
 	router.Use(gin.Recovery())              // Recover from panics
 	router.Use(middleware.Logger())         // Custom logger for API tracing
 
+	// Initialize mail delivery
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
+
 	// Initialize auth module
+	if !auth.VerificationMode(cfg.EmailVerificationMode).IsValid() {
+		log.Fatalf("Invalid EMAIL_VERIFICATION_MODE %q: use off, read_only or blocked", cfg.EmailVerificationMode)
+	}
 	authRepo := auth.NewRepository(db)
-	authService := auth.NewService(authRepo)
+	authService := auth.NewService(authRepo, mail, cfg)
 	authController := auth.NewController(authService)
-	auth.RegisterRoutes(router, authController)
+	auth.RegisterRoutes(router, authController, cfg.JWTSecret)
+
+	// Delete sessions and refresh tokens that can no longer be used
+	go auth.RunSessionPurge(context.Background(), authService, time.Hour)
 
 	// Initialize vocab module
 	vocabRepo := vocab.NewRepository(db)
 	vocabService := vocab.NewService(vocabRepo)
 	vocabController := vocab.NewController(vocabService)
-	vocab.RegisterRoutes(router, vocabController, cfg.JWTSecret)
+	vocab.RegisterRoutes(router, vocabController, cfg.JWTSecret, authService)
+
+	// Permanently delete vocabularies left in the trash past the retention period
+	if cfg.TrashRetentionDays > 0 {
+		retention := time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour
+		go vocab.RunTrashPurge(context.Background(), vocabService, retention, time.Hour)
+	}
+
+	// Initialize deck module
+	deckRepo := deck.NewRepository(db)
+	deckService := deck.NewService(deckRepo, vocabService)
+	deckController := deck.NewController(deckService)
+	deck.RegisterRoutes(router, deckController, cfg.JWTSecret, authService)
+
+	// Initialize quiz module
+	quizRepo := quiz.NewRepository(db)
+	quizService := quiz.NewService(quizRepo, vocabService)
+	quizController := quiz.NewController(quizService)
+	quiz.RegisterRoutes(router, quizController, cfg.JWTSecret, authService)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.Port)
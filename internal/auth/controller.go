@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 
+	"vocabulary-app-be/pkg/middleware"
 	"vocabulary-app-be/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -19,13 +21,48 @@ func NewController(service Service) *Controller {
 }
 
 // RegisterRoutes registers auth routes
-func RegisterRoutes(router *gin.Engine, c *Controller) {
+func RegisterRoutes(router *gin.Engine, c *Controller, jwtSecret string) {
 	auth := router.Group("/api/auth")
 	{
 		auth.POST("/login", c.Login)
 		auth.POST("/register", c.Register)
+		auth.POST("/refresh", c.Refresh)
 		auth.POST("/logout", c.Logout)
+		auth.POST("/password/forgot", c.ForgotPassword)
+		auth.POST("/password/reset", c.ResetPassword)
+		auth.POST("/verify-email", c.VerifyEmail)
+		auth.POST("/verify-email/resend", c.ResendVerification)
 	}
+
+	account := router.Group("/api/auth")
+	// Add auth middleware; unverified users may still manage their account
+	account.Use(middleware.AuthMiddleware(jwtSecret, accountSessions{c.service}))
+	{
+		account.GET("/me", c.GetMe)
+		account.PATCH("/me", c.UpdateMe)
+		account.DELETE("/me", c.DeleteMe)
+		account.POST("/password/change", c.ChangePassword)
+		account.GET("/sessions", c.GetSessions)
+		account.DELETE("/sessions/:id", c.RevokeSession)
+	}
+}
+
+// refreshCookiePath limits the refresh token cookie to the auth endpoints
+const refreshCookiePath = "/api/auth"
+
+// accountSessions checks sessions for the account endpoints, which stay writable
+// for unverified users in read-only mode
+type accountSessions struct {
+	service Service
+}
+
+// CheckSession implements middleware.SessionChecker
+func (a accountSessions) CheckSession(ctx context.Context, userID, sessionID string) (middleware.SessionAccess, error) {
+	access, err := a.service.CheckSession(ctx, userID, sessionID)
+	if access == middleware.AccessReadOnly {
+		access = middleware.AccessFull
+	}
+	return access, err
 }
 
 // Login handles user login
@@ -37,28 +74,21 @@ func (c *Controller) Login(ctx *gin.Context) {
 		return
 	}
 
-	response, err := c.service.Login(ctx.Request.Context(), &req)
+	response, err := c.service.Login(ctx.Request.Context(), &req, clientInfo(ctx))
 	if err != nil {
 		ctx.Error(err)
 		switch err {
 		case ErrInvalidCredentials:
 			utils.ErrorResponse(ctx, http.StatusUnauthorized, "Invalid email or password")
+		case ErrEmailNotVerified:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Please verify your email address to log in")
 		default:
 			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Internal server error")
 		}
 		return
 	}
 
-	// Set HTTP-only cookie with token
-	ctx.SetCookie(
-		"auth_token",
-		response.Token,
-		86400,
-		"/",
-		"",
-		true,
-		true,
-	)
+	setAuthCookies(ctx, response)
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Login successful", response)
 }
@@ -72,7 +102,7 @@ func (c *Controller) Register(ctx *gin.Context) {
 		return
 	}
 
-	response, err := c.service.Register(ctx.Request.Context(), &req)
+	response, err := c.service.Register(ctx.Request.Context(), &req, clientInfo(ctx))
 	if err != nil {
 		ctx.Error(err)
 		switch err {
@@ -84,23 +114,391 @@ func (c *Controller) Register(ctx *gin.Context) {
 		return
 	}
 
-	// Set HTTP-only cookie with token
+	// No session is started when unverified accounts are blocked
+	if response.Token != "" {
+		setAuthCookies(ctx, response)
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "Registration successful", response)
+}
+
+// Refresh handles access token renewal. The refresh token is rotated on every call.
+func (c *Controller) Refresh(ctx *gin.Context) {
+	refreshToken, err := refreshTokenFromRequest(ctx)
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if refreshToken == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Refresh token required")
+		return
+	}
+
+	response, err := c.service.Refresh(ctx.Request.Context(), refreshToken, clientInfo(ctx))
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidRefreshToken:
+			clearAuthCookies(ctx)
+			utils.ErrorResponse(ctx, http.StatusUnauthorized, "Invalid or expired refresh token")
+		case ErrRefreshTokenReused:
+			clearAuthCookies(ctx)
+			utils.ErrorResponse(ctx, http.StatusUnauthorized, "Refresh token was already used, please log in again")
+		case ErrEmailNotVerified:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Please verify your email address to log in")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	setAuthCookies(ctx, response)
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Token refreshed", response)
+}
+
+// Logout handles user logout and revokes the refresh token family of the session
+func (c *Controller) Logout(ctx *gin.Context) {
+	refreshToken, err := refreshTokenFromRequest(ctx)
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.service.Logout(ctx.Request.Context(), refreshToken); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to revoke refresh token")
+		return
+	}
+
+	clearAuthCookies(ctx)
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Logout successful", nil)
+}
+
+// ForgotPassword handles password reset link requests. The response is the same
+// whether or not an account exists for the email.
+func (c *Controller) ForgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.service.ForgotPassword(ctx.Request.Context(), &req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "If an account exists for this email, a password reset link has been sent", nil)
+}
+
+// ResetPassword handles setting a new password with a reset token
+func (c *Controller) ResetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.service.ResetPassword(ctx.Request.Context(), &req); err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidResetToken:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid or expired password reset token")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	// Every session was revoked, including the one of this browser if any
+	clearAuthCookies(ctx)
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Password has been reset, please log in with your new password", nil)
+}
+
+// VerifyEmail handles email verification with the token sent on registration
+func (c *Controller) VerifyEmail(ctx *gin.Context) {
+	var req VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.service.VerifyEmail(ctx.Request.Context(), &req); err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidVerificationToken:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid or expired verification token")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Email verified successfully", nil)
+}
+
+// ResendVerification handles verification email resend requests. The response is the same
+// whether or not an unverified account exists for the email.
+func (c *Controller) ResendVerification(ctx *gin.Context) {
+	var req ResendVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.service.ResendVerification(ctx.Request.Context(), &req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "If this email needs verification, a new link has been sent", nil)
+}
+
+// GetMe handles retrieving the logged-in user
+func (c *Controller) GetMe(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	user, err := c.service.GetUserByID(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrUserNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "User not found")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve user")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "User retrieved successfully", user)
+}
+
+// UpdateMe handles updating the name and email of the logged-in user
+func (c *Controller) UpdateMe(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := c.service.UpdateProfile(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrUserNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "User not found")
+		case ErrInvalidName:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Name cannot be empty")
+		case ErrPasswordRequired:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Current password is required to change the email")
+		case ErrIncorrectPassword:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Current password is incorrect")
+		case ErrUserAlreadyExists:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Email is already in use")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update user")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "User updated successfully", user)
+}
+
+// DeleteMe handles deleting the account of the logged-in user with all their data
+func (c *Controller) DeleteMe(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.service.DeleteAccount(ctx.Request.Context(), userID, &req); err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrUserNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "User not found")
+		case ErrIncorrectPassword:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Password is incorrect")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete account")
+		}
+		return
+	}
+
+	clearAuthCookies(ctx)
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Account deleted successfully", nil)
+}
+
+// ChangePassword handles changing the password of the logged-in user.
+// Other sessions are logged out; this one stays logged in.
+func (c *Controller) ChangePassword(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.service.ChangePassword(ctx.Request.Context(), userID, ctx.GetString("sessionID"), &req); err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrUserNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "User not found")
+		case ErrIncorrectPassword:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Current password is incorrect")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to change password")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Password changed successfully", nil)
+}
+
+// GetSessions handles listing the active login sessions of the user
+func (c *Controller) GetSessions(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessions, err := c.service.GetSessions(ctx.Request.Context(), userID, ctx.GetString("sessionID"))
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve sessions")
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession handles revoking a login session of the user
+func (c *Controller) RevokeSession(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := c.service.RevokeSession(ctx.Request.Context(), userID, id); err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrSessionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Session not found")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to revoke session")
+		}
+		return
+	}
+
+	// Revoking the current session logs the caller out
+	if id == ctx.GetString("sessionID") {
+		clearAuthCookies(ctx)
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Session revoked successfully", nil)
+}
+
+// getUserID extracts user ID from context (set by auth middleware)
+func getUserID(ctx *gin.Context) string {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		return ""
+	}
+	return userID.(string)
+}
+
+// clientInfo describes the client making the request
+func clientInfo(ctx *gin.Context) ClientInfo {
+	return ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+}
+
+// refreshTokenFromRequest reads the refresh token from the JSON body, falling back to the refresh_token cookie
+func refreshTokenFromRequest(ctx *gin.Context) (string, error) {
+	var req RefreshRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return "", err
+		}
+	}
+	if req.RefreshToken != "" {
+		return req.RefreshToken, nil
+	}
+
+	token, _ := ctx.Cookie("refresh_token")
+	return token, nil
+}
+
+// setAuthCookies sets HTTP-only cookies with the access and refresh tokens
+func setAuthCookies(ctx *gin.Context, response *AuthResponse) {
 	ctx.SetCookie(
 		"auth_token",
 		response.Token,
-		86400,
+		response.ExpiresIn,
 		"/",
 		"",
 		true,
 		true,
 	)
-
-	utils.SuccessResponse(ctx, http.StatusCreated, "Registration successful", response)
+	ctx.SetCookie(
+		"refresh_token",
+		response.RefreshToken,
+		response.RefreshExpiresIn,
+		refreshCookiePath,
+		"",
+		true,
+		true,
+	)
 }
 
-// Logout handles user logout
-func (c *Controller) Logout(ctx *gin.Context) {
-	// Clear the auth cookie
+// clearAuthCookies clears the access and refresh token cookies
+func clearAuthCookies(ctx *gin.Context) {
 	ctx.SetCookie(
 		"auth_token",
 		"",
@@ -110,6 +508,13 @@ func (c *Controller) Logout(ctx *gin.Context) {
 		true,
 		true,
 	)
-
-	utils.SuccessResponse(ctx, http.StatusOK, "Logout successful", nil)
+	ctx.SetCookie(
+		"refresh_token",
+		"",
+		-1,
+		refreshCookiePath,
+		"",
+		true,
+		true,
+	)
 }
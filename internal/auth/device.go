@@ -0,0 +1,58 @@
+package auth
+
+import "strings"
+
+// userAgentPattern maps a user agent substring to a readable name. Order matters:
+// the first match wins, so more specific tokens come first.
+type userAgentPattern struct {
+	token string
+	name  string
+}
+
+var browserPatterns = []userAgentPattern{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"okhttp", "Android app"},
+	{"CFNetwork", "iOS app"},
+	{"Dart/", "Mobile app"},
+}
+
+var platformPatterns = []userAgentPattern{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// describeDevice returns a short description such as "Chrome on Windows" for a user agent
+func describeDevice(userAgent string) string {
+	browser := matchUserAgent(userAgent, browserPatterns)
+	platform := matchUserAgent(userAgent, platformPatterns)
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
+
+// matchUserAgent returns the name of the first pattern found in the user agent
+func matchUserAgent(userAgent string, patterns []userAgentPattern) string {
+	for _, pattern := range patterns {
+		if strings.Contains(userAgent, pattern.token) {
+			return pattern.name
+		}
+	}
+	return ""
+}
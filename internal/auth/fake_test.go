@@ -0,0 +1,360 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"vocabulary-app-be/internal/testutil"
+	"vocabulary-app-be/pkg/mailer"
+)
+
+// fakeRepository is an in-memory Repository with the semantics of the SQL repository
+type fakeRepository struct {
+	mu       sync.Mutex
+	ids      testutil.Sequence
+	users    *testutil.Table[User]
+	sessions *testutil.Table[Session]
+	// refreshTokens are keyed by token hash
+	refreshTokens      *testutil.Table[RefreshToken]
+	resetTokens        []*fakeToken
+	verificationTokens []*fakeToken
+
+	// rotateRace marks a token as rotated by a concurrent request before RotateRefreshToken runs
+	rotateRace bool
+	// verificationErr is returned by CreateEmailVerificationToken when set
+	verificationErr error
+}
+
+// fakeToken is a stored password reset or email verification token
+type fakeToken struct {
+	userID    string
+	email     string
+	hash      string
+	expiresAt time.Time
+	createdAt time.Time
+	used      bool
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		users:         testutil.NewTable[User](),
+		sessions:      testutil.NewTable[Session](),
+		refreshTokens: testutil.NewTable[RefreshToken](),
+	}
+}
+
+func (r *fakeRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.users.Find(func(user *User) bool { return user.Email == email }), nil
+}
+
+func (r *fakeRepository) Create(ctx context.Context, user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.users.Find(func(existing *User) bool { return existing.Email == user.Email }) != nil {
+		return ErrUserAlreadyExists
+	}
+	user.ID = r.ids.Next()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	r.users.Insert(user.ID, *user)
+	return nil
+}
+
+func (r *fakeRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.users.Get(id), nil
+}
+
+func (r *fakeRepository) CreateSession(ctx context.Context, session *Session, token *RefreshToken, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session.ID = r.ids.Next()
+	session.CreatedAt = time.Now()
+	session.LastSeenAt = session.CreatedAt
+	session.ExpiresAt = session.CreatedAt.Add(ttl)
+	r.sessions.Insert(session.ID, *session)
+
+	token.UserID = session.UserID
+	token.FamilyID = session.ID
+	r.storeRefreshToken(token, ttl)
+	return nil
+}
+
+func (r *fakeRepository) storeRefreshToken(token *RefreshToken, ttl time.Duration) {
+	token.ID = r.ids.Next()
+	token.CreatedAt = time.Now()
+	token.ExpiresAt = token.CreatedAt.Add(ttl)
+	r.refreshTokens.Insert(token.TokenHash, *token)
+}
+
+func (r *fakeRepository) FindSessionByID(ctx context.Context, id string) (*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessions.Get(id), nil
+}
+
+func (r *fakeRepository) FindSessionsByUserID(ctx context.Context, userID string) ([]Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessions.Filter(func(session *Session) bool {
+		return session.UserID == userID && session.RevokedAt == nil
+	}), nil
+}
+
+func (r *fakeRepository) TouchSession(ctx context.Context, userID, id string) (bool, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session := r.sessions.Row(id)
+	if session == nil || session.UserID != userID || session.RevokedAt != nil || session.ExpiresAt.Before(time.Now()) {
+		return false, false, nil
+	}
+	session.LastSeenAt = time.Now()
+	return true, r.users.Row(userID).EmailVerified(), nil
+}
+
+func (r *fakeRepository) RevokeSession(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokeSession(id)
+	return nil
+}
+
+func (r *fakeRepository) revokeSession(id string) {
+	now := time.Now()
+	if session := r.sessions.Row(id); session != nil && session.RevokedAt == nil {
+		session.RevokedAt = &now
+	}
+	r.refreshTokens.Each(func(token *RefreshToken) {
+		if token.FamilyID == id && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	})
+}
+
+func (r *fakeRepository) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (*RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token := r.refreshTokens.Get(hash)
+	if token == nil || token.ExpiresAt.Before(time.Now()) {
+		return nil, nil
+	}
+	return token, nil
+}
+
+func (r *fakeRepository) RotateRefreshToken(ctx context.Context, current *RefreshToken, next *RefreshToken, client ClientInfo, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.refreshTokens.Row(current.TokenHash)
+	now := time.Now()
+	if r.rotateRace && stored.UsedAt == nil {
+		stored.UsedAt = &now
+	}
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		return ErrRefreshTokenReused
+	}
+	stored.UsedAt = &now
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	r.storeRefreshToken(next, ttl)
+
+	session := r.sessions.Row(current.FamilyID)
+	session.UserAgent = client.UserAgent
+	session.IPAddress = client.IPAddress
+	session.ExpiresAt = now.Add(ttl)
+	return nil
+}
+
+func (r *fakeRepository) DeleteExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) CreatePasswordResetToken(ctx context.Context, userID, hash string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.resetTokens = append(r.resetTokens, &fakeToken{userID: userID, hash: hash, expiresAt: now.Add(ttl), createdAt: now})
+	return nil
+}
+
+func (r *fakeRepository) CountRecentPasswordResetTokens(ctx context.Context, userID string, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return countRecent(r.resetTokens, userID, window), nil
+}
+
+func (r *fakeRepository) ResetPassword(ctx context.Context, tokenHash, password string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token := findOpenToken(r.resetTokens, tokenHash)
+	if token == nil {
+		return ErrInvalidResetToken
+	}
+
+	r.users.Row(token.userID).Password = password
+	for _, other := range r.resetTokens {
+		if other.userID == token.userID {
+			other.used = true
+		}
+	}
+	r.revokeSessions(token.userID, "")
+	return nil
+}
+
+func (r *fakeRepository) DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) CreateEmailVerificationToken(ctx context.Context, userID, email, hash string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.verificationErr != nil {
+		return r.verificationErr
+	}
+	now := time.Now()
+	r.verificationTokens = append(r.verificationTokens, &fakeToken{userID: userID, email: email, hash: hash, expiresAt: now.Add(ttl), createdAt: now})
+	return nil
+}
+
+func (r *fakeRepository) CountRecentEmailVerificationTokens(ctx context.Context, userID string, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return countRecent(r.verificationTokens, userID, window), nil
+}
+
+func (r *fakeRepository) VerifyEmail(ctx context.Context, tokenHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token := findOpenToken(r.verificationTokens, tokenHash)
+	if token == nil || r.users.Row(token.userID).Email != token.email {
+		return ErrInvalidVerificationToken
+	}
+
+	token.used = true
+	now := time.Now()
+	r.users.Row(token.userID).EmailVerifiedAt = &now
+	return nil
+}
+
+func (r *fakeRepository) DeleteExpiredEmailVerificationTokens(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) UpdateProfile(ctx context.Context, user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.users.Row(user.ID).Email != user.Email {
+		user.EmailVerifiedAt = nil
+	}
+	r.users.Insert(user.ID, *user)
+	return nil
+}
+
+func (r *fakeRepository) UpdatePassword(ctx context.Context, userID, password, keepSessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users.Row(userID).Password = password
+	r.revokeSessions(userID, keepSessionID)
+	return nil
+}
+
+func (r *fakeRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users.Delete(id)
+	return nil
+}
+
+// revokeSessions revokes every session of the user other than keepSessionID
+func (r *fakeRepository) revokeSessions(userID, keepSessionID string) {
+	for _, session := range r.sessions.Filter(func(session *Session) bool { return session.UserID == userID }) {
+		if session.ID != keepSessionID {
+			r.revokeSession(session.ID)
+		}
+	}
+}
+
+// countRecent counts the tokens of the user created within window
+func countRecent(tokens []*fakeToken, userID string, window time.Duration) int {
+	count := 0
+	for _, token := range tokens {
+		if token.userID == userID && token.createdAt.After(time.Now().Add(-window)) {
+			count++
+		}
+	}
+	return count
+}
+
+// findOpenToken finds an unused, unexpired token by hash
+func findOpenToken(tokens []*fakeToken, hash string) *fakeToken {
+	for _, token := range tokens {
+		if token.hash == hash && !token.used && token.expiresAt.After(time.Now()) {
+			return token
+		}
+	}
+	return nil
+}
+
+// recordingMailer records sent emails. Emails are sent in the background, so tests wait for them.
+type recordingMailer struct {
+	sent chan *mailer.Message
+}
+
+func newRecordingMailer() *recordingMailer {
+	return &recordingMailer{sent: make(chan *mailer.Message, 16)}
+}
+
+// Send implements mailer.Mailer
+func (m *recordingMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	m.sent <- msg
+	return nil
+}
+
+// wait returns the next sent email, failing the test if none arrives
+func (m *recordingMailer) wait(t *testing.T) *mailer.Message {
+	t.Helper()
+	select {
+	case msg := <-m.sent:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no email was sent")
+		return nil
+	}
+}
+
+// expectNone fails the test if an email is sent shortly after the call
+func (m *recordingMailer) expectNone(t *testing.T) {
+	t.Helper()
+	select {
+	case msg := <-m.sent:
+		t.Fatalf("unexpected email %q to %s", msg.Subject, msg.To)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+var mailTokenPattern = regexp.MustCompile(`\?token=(\S+)`)
+
+// mailToken extracts the token of the link in an email
+func mailToken(t *testing.T, msg *mailer.Message) string {
+	t.Helper()
+	match := mailTokenPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("email %q holds no token link", msg.Subject)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("unescape token: %v", err)
+	}
+	return token
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"vocabulary-app-be/pkg/mailer"
+)
+
+// passwordResetMessage builds the email holding a password reset link
+func passwordResetMessage(user *User, link string, ttl time.Duration) *mailer.Message {
+	return &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(`Hi %s,
+
+We received a request to reset the password of your Vocabulary App account.
+Open the link below to choose a new password:
+
+%s
+
+The link expires in %s and can be used once. If you did not ask for a new password,
+you can ignore this email; your password stays unchanged.
+`, user.Name, link, formatDuration(ttl)),
+	}
+}
+
+// emailVerificationMessage builds the email holding an email verification link
+func emailVerificationMessage(user *User, link string, ttl time.Duration) *mailer.Message {
+	return &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(`Hi %s,
+
+Please confirm that %s is your email address by opening the link below:
+
+%s
+
+The link expires in %s. If you did not create a Vocabulary App account,
+you can ignore this email.
+`, user.Name, user.Email, link, formatDuration(ttl)),
+	}
+}
+
+// formatDuration formats a token lifetime as minutes, hours or days
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d/time.Hour))
+	case d >= 2*time.Minute:
+		return fmt.Sprintf("%d minutes", int(d/time.Minute))
+	default:
+		return "1 minute"
+	}
+}
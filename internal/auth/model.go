@@ -4,12 +4,39 @@ import "time"
 
 // User represents the user domain model
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	Password        string     `json:"-"`
+	Name            string     `json:"name"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// EmailVerified reports whether the user verified their current email address
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// VerificationMode controls what users can do before verifying their email
+type VerificationMode string
+
+const (
+	// VerificationOff lets unverified users do everything
+	VerificationOff VerificationMode = "off"
+	// VerificationReadOnly lets unverified users log in and read, but not make changes
+	VerificationReadOnly VerificationMode = "read_only"
+	// VerificationBlocked keeps unverified users from logging in
+	VerificationBlocked VerificationMode = "blocked"
+)
+
+// IsValid checks if the verification mode is valid
+func (m VerificationMode) IsValid() bool {
+	switch m {
+	case VerificationOff, VerificationReadOnly, VerificationBlocked:
+		return true
+	}
+	return false
 }
 
 // LoginRequest represents the login request payload
@@ -25,9 +52,93 @@ type RegisterRequest struct {
 	Name     string `json:"name" binding:"required"`
 }
 
+// RefreshRequest represents the refresh and logout request payload.
+// Browsers send the refresh token in the refresh_token cookie instead.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ForgotPasswordRequest represents the password reset link request payload
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// VerifyEmailRequest represents the email verification payload
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest represents the verification email resend payload
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents the password reset payload
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// UpdateProfileRequest represents the profile update payload. Changing the email
+// requires the current password and a new verification of the address.
+type UpdateProfileRequest struct {
+	Name            *string `json:"name" binding:"omitempty,max=255"`
+	Email           *string `json:"email" binding:"omitempty,email,max=255"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+// ChangePasswordRequest represents the password change payload
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// DeleteAccountRequest represents the account deletion payload
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
 // AuthResponse represents the authentication response
 type AuthResponse struct {
-	Token   string `json:"token,omitempty"`
-	User    User   `json:"user"`
-	Message string `json:"message,omitempty"`
+	Token            string `json:"token,omitempty"`
+	ExpiresIn        int    `json:"expires_in,omitempty"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	RefreshExpiresIn int    `json:"refresh_expires_in,omitempty"`
+	User             User   `json:"user"`
+	Message          string `json:"message,omitempty"`
+}
+
+// RefreshToken is a stored refresh token. Only the SHA-256 hash of the token is kept.
+// The tokens of a family all belong to the login session with the family ID; presenting
+// a token that was already rotated revokes the whole family and its session.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// Session is a login session: one per login, kept alive by refreshing its tokens
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"-"`
+	UserAgent  string    `json:"user_agent"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+
+	RevokedAt *time.Time `json:"-"`
+}
+
+// ClientInfo describes the client a login or refresh request comes from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
 }
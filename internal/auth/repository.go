@@ -3,6 +3,9 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // Repository handles data access for auth
@@ -10,6 +13,26 @@ type Repository interface {
 	FindByEmail(ctx context.Context, email string) (*User, error)
 	Create(ctx context.Context, user *User) error
 	FindByID(ctx context.Context, id string) (*User, error)
+	CreateSession(ctx context.Context, session *Session, token *RefreshToken, ttl time.Duration) error
+	FindSessionByID(ctx context.Context, id string) (*Session, error)
+	FindSessionsByUserID(ctx context.Context, userID string) ([]Session, error)
+	TouchSession(ctx context.Context, userID, id string) (bool, bool, error)
+	RevokeSession(ctx context.Context, id string) error
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	FindRefreshTokenByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, current *RefreshToken, next *RefreshToken, client ClientInfo, ttl time.Duration) error
+	DeleteExpiredRefreshTokens(ctx context.Context) (int64, error)
+	CreatePasswordResetToken(ctx context.Context, userID, hash string, ttl time.Duration) error
+	CountRecentPasswordResetTokens(ctx context.Context, userID string, window time.Duration) (int, error)
+	ResetPassword(ctx context.Context, tokenHash, password string) error
+	DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error)
+	CreateEmailVerificationToken(ctx context.Context, userID, email, hash string, ttl time.Duration) error
+	CountRecentEmailVerificationTokens(ctx context.Context, userID string, window time.Duration) (int, error)
+	VerifyEmail(ctx context.Context, tokenHash string) error
+	DeleteExpiredEmailVerificationTokens(ctx context.Context) (int64, error)
+	UpdateProfile(ctx context.Context, user *User) error
+	UpdatePassword(ctx context.Context, userID, password, keepSessionID string) error
+	Delete(ctx context.Context, id string) error
 }
 
 type repository struct {
@@ -21,20 +44,38 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-// FindByEmail finds a user by email
-func (r *repository) FindByEmail(ctx context.Context, email string) (*User, error) {
-	query := `SELECT id, email, password, name, created_at, updated_at FROM users WHERE email = $1`
+const userColumns = `id, email, password, name, email_verified_at, created_at, updated_at`
 
-	var user User
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+const sessionColumns = `id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at`
+
+// scanUser scans a row selected with userColumns into a User
+func scanUser(row rowScanner, user *User) error {
+	var verifiedAt sql.NullTime
+	if err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.Password,
 		&user.Name,
+		&verifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
-	)
-	if err != nil {
+	); err != nil {
+		return err
+	}
+
+	user.EmailVerifiedAt = nil
+	if verifiedAt.Valid {
+		user.EmailVerifiedAt = &verifiedAt.Time
+	}
+	return nil
+}
+
+// FindByEmail finds a user by email
+func (r *repository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+
+	var user User
+	if err := scanUser(r.db.QueryRowContext(ctx, query, email), &user); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -44,28 +85,29 @@ func (r *repository) FindByEmail(ctx context.Context, email string) (*User, erro
 	return &user, nil
 }
 
-// Create creates a new user
+// Create creates a new user.
+// ErrUserAlreadyExists is returned when another user has the email.
 func (r *repository) Create(ctx context.Context, user *User) error {
-	query := `INSERT INTO users (email, password, name, created_at, updated_at) 
-			  VALUES ($1, $2, $3, NOW(), NOW()) RETURNING id`
+	query := `INSERT INTO users (email, password, name, email_verified_at, created_at, updated_at) 
+			  VALUES ($1, $2, $3, NULL, NOW(), NOW()) RETURNING ` + userColumns
 
-	return r.db.QueryRowContext(ctx, query, user.Email, user.Password, user.Name).Scan(&user.ID)
+	return emailTaken(scanUser(r.db.QueryRowContext(ctx, query, user.Email, user.Password, user.Name), user))
+}
+
+// emailTaken maps a violation of the unique email constraint to ErrUserAlreadyExists
+func emailTaken(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return ErrUserAlreadyExists
+	}
+	return err
 }
 
 // FindByID finds a user by ID
 func (r *repository) FindByID(ctx context.Context, id string) (*User, error) {
-	query := `SELECT id, email, password, name, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 
 	var user User
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Password,
-		&user.Name,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-	if err != nil {
+	if err := scanUser(r.db.QueryRowContext(ctx, query, id), &user); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -74,3 +116,446 @@ func (r *repository) FindByID(ctx context.Context, id string) (*User, error) {
 
 	return &user, nil
 }
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRefreshToken scans a row selected with refreshTokenColumns into a RefreshToken
+func scanRefreshToken(row rowScanner, token *RefreshToken) error {
+	var usedAt, revokedAt sql.NullTime
+	if err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&usedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	token.UsedAt, token.RevokedAt = nil, nil
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return nil
+}
+
+// rowQuerier is implemented by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// createRefreshToken inserts a refresh token expiring after ttl using db, which may be a transaction
+func createRefreshToken(ctx context.Context, db rowQuerier, token *RefreshToken, ttl time.Duration) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second', NOW())
+			  RETURNING ` + refreshTokenColumns
+
+	return scanRefreshToken(db.QueryRowContext(ctx, query,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		int64(ttl/time.Second),
+	), token)
+}
+
+// FindRefreshTokenByHash finds a refresh token by the hash of its value. Expired tokens are not returned.
+func (r *repository) FindRefreshTokenByHash(ctx context.Context, hash string) (*RefreshToken, error) {
+	query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = $1 AND expires_at > NOW()`
+
+	var token RefreshToken
+	if err := scanRefreshToken(r.db.QueryRowContext(ctx, query, hash), &token); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RotateRefreshToken marks current as used, stores next in the same family and extends the session
+// of the family in a single transaction.
+// ErrRefreshTokenReused is returned when current was used or revoked in the meantime.
+func (r *repository) RotateRefreshToken(ctx context.Context, current *RefreshToken, next *RefreshToken, client ClientInfo, ttl time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE refresh_tokens SET used_at = NOW()
+			  WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, current.ID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrRefreshTokenReused
+	}
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	if err := createRefreshToken(ctx, tx, next, ttl); err != nil {
+		return err
+	}
+
+	sessionQuery := `UPDATE user_sessions
+					 SET user_agent = $1, ip_address = $2, last_seen_at = NOW(), expires_at = NOW() + $3 * INTERVAL '1 second'
+					 WHERE id = $4`
+	_, err = tx.ExecContext(ctx, sessionQuery, client.UserAgent, client.IPAddress, int64(ttl/time.Second), current.FamilyID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteExpiredRefreshTokens permanently deletes expired refresh tokens and returns how many were deleted
+func (r *repository) DeleteExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	query := `DELETE FROM refresh_tokens WHERE expires_at <= NOW()`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanSession scans a row selected with sessionColumns into a Session
+func scanSession(row rowScanner, session *Session) error {
+	var revokedAt sql.NullTime
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&revokedAt,
+	); err != nil {
+		return err
+	}
+
+	session.RevokedAt = nil
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return nil
+}
+
+// CreateSession creates a session expiring after ttl and its first refresh token in a single transaction
+func (r *repository) CreateSession(ctx context.Context, session *Session, token *RefreshToken, ttl time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO user_sessions (user_id, user_agent, ip_address, created_at, last_seen_at, expires_at)
+			  VALUES ($1, $2, $3, NOW(), NOW(), NOW() + $4 * INTERVAL '1 second') RETURNING ` + sessionColumns
+
+	err = scanSession(tx.QueryRowContext(ctx, query,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		int64(ttl/time.Second),
+	), session)
+	if err != nil {
+		return err
+	}
+
+	token.UserID = session.UserID
+	token.FamilyID = session.ID
+	if err := createRefreshToken(ctx, tx, token, ttl); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindSessionByID finds a session by ID
+func (r *repository) FindSessionByID(ctx context.Context, id string) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE id = $1`
+
+	var session Session
+	if err := scanSession(r.db.QueryRowContext(ctx, query, id), &session); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindSessionsByUserID finds the active sessions of a user, most recently seen first
+func (r *repository) FindSessionsByUserID(ctx context.Context, userID string) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions
+			  WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+			  ORDER BY last_seen_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		if err := scanSession(rows, &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// TouchSession reports whether a session of the user is still active and whether the user
+// verified their email, and records the session as seen. last_seen_at is written at most once a minute.
+func (r *repository) TouchSession(ctx context.Context, userID, id string) (bool, bool, error) {
+	query := `WITH active AS (
+				  SELECT id FROM user_sessions WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+			  ), touched AS (
+				  UPDATE user_sessions SET last_seen_at = NOW()
+				  WHERE id IN (SELECT id FROM active) AND last_seen_at < NOW() - INTERVAL '1 minute'
+			  )
+			  SELECT EXISTS (SELECT 1 FROM active),
+			         EXISTS (SELECT 1 FROM users WHERE id = $2 AND email_verified_at IS NOT NULL)`
+
+	var active, verified bool
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(&active, &verified)
+	return active, verified, err
+}
+
+// RevokeSession revokes a session and every refresh token issued for it
+func (r *repository) RevokeSession(ctx context.Context, id string) error {
+	query := `WITH tokens AS (
+				  UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL
+			  )
+			  UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// DeleteExpiredSessions permanently deletes expired sessions with their refresh tokens
+// and returns how many sessions were deleted
+func (r *repository) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	query := `DELETE FROM user_sessions WHERE expires_at <= NOW()`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CreatePasswordResetToken stores the hash of a password reset token expiring after ttl
+func (r *repository) CreatePasswordResetToken(ctx context.Context, userID, hash string, ttl time.Duration) error {
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second', NOW())`
+
+	_, err := r.db.ExecContext(ctx, query, userID, hash, int64(ttl/time.Second))
+	return err
+}
+
+// CountRecentPasswordResetTokens counts the password reset tokens created for the user within window
+func (r *repository) CountRecentPasswordResetTokens(ctx context.Context, userID string, window time.Duration) (int, error) {
+	query := `SELECT COUNT(*) FROM password_reset_tokens
+			  WHERE user_id = $1 AND created_at > NOW() - $2 * INTERVAL '1 second'`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID, int64(window/time.Second)).Scan(&count)
+	return count, err
+}
+
+// ResetPassword consumes a password reset token and sets the password of its user in a single transaction.
+// Other reset tokens of the user are invalidated and all their sessions revoked.
+// ErrInvalidResetToken is returned when the token is unknown, used or expired.
+func (r *repository) ResetPassword(ctx context.Context, tokenHash, password string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID string
+	query := `UPDATE password_reset_tokens SET used_at = NOW()
+			  WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+			  RETURNING user_id`
+	if err := tx.QueryRowContext(ctx, query, tokenHash).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`, password, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return err
+	}
+	if err := revokeUserSessions(ctx, tx, userID, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// revokeUserSessions revokes the sessions of a user and their refresh tokens using db, which may be a transaction.
+// The session with ID keepSessionID, if any, is left active.
+func revokeUserSessions(ctx context.Context, db execer, userID, keepSessionID string) error {
+	query := `WITH tokens AS (
+				  UPDATE refresh_tokens SET revoked_at = NOW()
+				  WHERE user_id = $1 AND family_id::TEXT <> $2 AND revoked_at IS NULL
+			  )
+			  UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND id::TEXT <> $2 AND revoked_at IS NULL`
+
+	_, err := db.ExecContext(ctx, query, userID, keepSessionID)
+	return err
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// DeleteExpiredPasswordResetTokens permanently deletes expired password reset tokens and returns how many were deleted
+func (r *repository) DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error) {
+	query := `DELETE FROM password_reset_tokens WHERE expires_at <= NOW()`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CreateEmailVerificationToken stores the hash of a token verifying email for the user, expiring after ttl
+func (r *repository) CreateEmailVerificationToken(ctx context.Context, userID, email, hash string, ttl time.Duration) error {
+	query := `INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second', NOW())`
+
+	_, err := r.db.ExecContext(ctx, query, userID, email, hash, int64(ttl/time.Second))
+	return err
+}
+
+// CountRecentEmailVerificationTokens counts the verification tokens created for the user within window
+func (r *repository) CountRecentEmailVerificationTokens(ctx context.Context, userID string, window time.Duration) (int, error) {
+	query := `SELECT COUNT(*) FROM email_verification_tokens
+			  WHERE user_id = $1 AND created_at > NOW() - $2 * INTERVAL '1 second'`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID, int64(window/time.Second)).Scan(&count)
+	return count, err
+}
+
+// VerifyEmail consumes an email verification token and marks the email of its user as verified
+// in a single transaction. ErrInvalidVerificationToken is returned when the token is unknown,
+// used or expired, or was sent to an address the user no longer has.
+func (r *repository) VerifyEmail(ctx context.Context, tokenHash string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID, email string
+	query := `UPDATE email_verification_tokens SET used_at = NOW()
+			  WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+			  RETURNING user_id, email`
+	if err := tx.QueryRowContext(ctx, query, tokenHash).Scan(&userID, &email); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	userQuery := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+				  WHERE id = $1 AND email = $2`
+	result, err := tx.ExecContext(ctx, userQuery, userID, email)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrInvalidVerificationToken
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE email_verification_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteExpiredEmailVerificationTokens permanently deletes expired email verification tokens and returns how many were deleted
+func (r *repository) DeleteExpiredEmailVerificationTokens(ctx context.Context) (int64, error) {
+	query := `DELETE FROM email_verification_tokens WHERE expires_at <= NOW()`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// UpdateProfile saves the name and email of a user. Changing the email clears its verification.
+// ErrUserAlreadyExists is returned when another user has the email.
+func (r *repository) UpdateProfile(ctx context.Context, user *User) error {
+	query := `UPDATE users
+			  SET name = $1,
+			      email = $2,
+			      email_verified_at = CASE WHEN email = $2 THEN email_verified_at END,
+			      updated_at = NOW()
+			  WHERE id = $3 RETURNING ` + userColumns
+
+	return emailTaken(scanUser(r.db.QueryRowContext(ctx, query, user.Name, user.Email, user.ID), user))
+}
+
+// UpdatePassword sets the password of a user in a single transaction with revoking every other
+// session and invalidating pending password reset tokens. The session keepSessionID stays active.
+func (r *repository) UpdatePassword(ctx context.Context, userID, password, keepSessionID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`, password, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return err
+	}
+	if err := revokeUserSessions(ctx, tx, userID, keepSessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete permanently deletes a user. Vocabularies, decks, sessions and every other
+// row owned by the user are deleted with it by ON DELETE CASCADE.
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	return err
+}
@@ -2,9 +2,18 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
 
 	"vocabulary-app-be/pkg/config"
+	"vocabulary-app-be/pkg/mailer"
 	"vocabulary-app-be/pkg/middleware"
 
 	"golang.org/x/crypto/bcrypt"
@@ -14,26 +23,81 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrUserNotFound       = errors.New("user not found")
+
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidResetToken   = errors.New("invalid password reset token")
+
+	ErrEmailNotVerified         = errors.New("email not verified")
+	ErrInvalidVerificationToken = errors.New("invalid email verification token")
+
+	ErrIncorrectPassword = errors.New("incorrect password")
+	ErrPasswordRequired  = errors.New("current password required")
+	ErrInvalidName       = errors.New("invalid name")
 )
 
+// secretTokenBytes is the number of random bytes in refresh, password reset and email verification tokens
+const secretTokenBytes = 32
+
+// verificationResendWindow is the minimum time between two verification emails to a user
+const verificationResendWindow = time.Minute
+
+// passwordResetWindow is the minimum time between two password reset emails to a user
+const passwordResetWindow = time.Minute
+
+// mailTimeout bounds the delivery of a single email
+const mailTimeout = 30 * time.Second
+
 // Service handles business logic for auth
 type Service interface {
-	Login(ctx context.Context, req *LoginRequest) (*AuthResponse, error)
-	Register(ctx context.Context, req *RegisterRequest) (*AuthResponse, error)
+	Login(ctx context.Context, req *LoginRequest, client ClientInfo) (*AuthResponse, error)
+	Register(ctx context.Context, req *RegisterRequest, client ClientInfo) (*AuthResponse, error)
 	GetUserByID(ctx context.Context, id string) (*User, error)
+	Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*AuthResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
+	GetSessions(ctx context.Context, userID, currentSessionID string) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	CheckSession(ctx context.Context, userID, sessionID string) (middleware.SessionAccess, error)
+	PurgeExpiredSessions(ctx context.Context) (int64, error)
+	ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, req *VerifyEmailRequest) error
+	ResendVerification(ctx context.Context, req *ResendVerificationRequest) error
+	UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) (*User, error)
+	ChangePassword(ctx context.Context, userID, sessionID string, req *ChangePasswordRequest) error
+	DeleteAccount(ctx context.Context, userID string, req *DeleteAccountRequest) error
 }
 
 type service struct {
-	repo Repository
+	repo             Repository
+	mailer           mailer.Mailer
+	jwtSecret        string
+	frontendURL      string
+	verificationMode VerificationMode
+	accessTTL        time.Duration
+	refreshTTL       time.Duration
+	resetTTL         time.Duration
+	verificationTTL  time.Duration
 }
 
 // NewService creates a new auth service
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, mailer mailer.Mailer, cfg *config.Config) Service {
+	return &service{
+		repo:             repo,
+		mailer:           mailer,
+		jwtSecret:        cfg.JWTSecret,
+		frontendURL:      cfg.FrontendURL,
+		verificationMode: VerificationMode(cfg.EmailVerificationMode),
+		accessTTL:        time.Duration(max(cfg.AccessTokenTTLMinutes, 1)) * time.Minute,
+		refreshTTL:       time.Duration(max(cfg.RefreshTokenTTLDays, 1)) * 24 * time.Hour,
+		resetTTL:         time.Duration(max(cfg.PasswordResetTTLMinutes, 1)) * time.Minute,
+		verificationTTL:  time.Duration(max(cfg.EmailVerificationTTLHours, 1)) * time.Hour,
+	}
 }
 
 // Login authenticates a user
-func (s *service) Login(ctx context.Context, req *LoginRequest) (*AuthResponse, error) {
+func (s *service) Login(ctx context.Context, req *LoginRequest, client ClientInfo) (*AuthResponse, error) {
 	user, err := s.repo.FindByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, err
@@ -46,21 +110,15 @@ func (s *service) Login(ctx context.Context, req *LoginRequest) (*AuthResponse,
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
 		return nil, ErrInvalidCredentials
 	}
-
-	// Generate JWT token
-	token, err := generateToken(user.ID, user.Email)
-	if err != nil {
-		return nil, err
+	if s.blocksUnverified(user) {
+		return nil, ErrEmailNotVerified
 	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  *user,
-	}, nil
+	return s.startSession(ctx, user, client)
 }
 
 // Register creates a new user
-func (s *service) Register(ctx context.Context, req *RegisterRequest) (*AuthResponse, error) {
+func (s *service) Register(ctx context.Context, req *RegisterRequest, client ClientInfo) (*AuthResponse, error) {
 	// Check if user already exists
 	existingUser, err := s.repo.FindByEmail(ctx, req.Email)
 	if err != nil {
@@ -87,16 +145,21 @@ func (s *service) Register(ctx context.Context, req *RegisterRequest) (*AuthResp
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := generateToken(user.ID, user.Email)
-	if err != nil {
-		return nil, err
+	// The account exists now, so a failed token does not fail the registration;
+	// the user can ask for a new link through ResendVerification
+	if err := s.sendVerification(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
 	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  *user,
-	}, nil
+	// Blocked accounts get no session until the email is verified
+	if s.blocksUnverified(user) {
+		return &AuthResponse{
+			User:    *user,
+			Message: "Please verify your email address to log in",
+		}, nil
+	}
+
+	return s.startSession(ctx, user, client)
 }
 
 // GetUserByID retrieves a user by ID
@@ -111,9 +174,403 @@ func (s *service) GetUserByID(ctx context.Context, id string) (*User, error) {
 	return user, nil
 }
 
-// generateToken generates a JWT token for the user
-func generateToken(userID string, email string) (string, error) {
-	cfg := config.Load()
-	token, err := middleware.GenerateToken(userID, email, cfg.JWTSecret, 24)
-	return token, err
+// UpdateProfile changes the name and email of a user. A new email must be confirmed
+// with the current password and is unverified until the link sent to it is opened.
+func (s *service) UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) (*User, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, ErrInvalidName
+		}
+		user.Name = name
+	}
+
+	emailChanged := req.Email != nil && *req.Email != user.Email
+	if emailChanged {
+		if req.CurrentPassword == "" {
+			return nil, ErrPasswordRequired
+		}
+		if err := checkPassword(user, req.CurrentPassword); err != nil {
+			return nil, err
+		}
+
+		existingUser, err := s.repo.FindByEmail(ctx, *req.Email)
+		if err != nil {
+			return nil, err
+		}
+		if existingUser != nil {
+			return nil, ErrUserAlreadyExists
+		}
+		user.Email = *req.Email
+	}
+
+	if err := s.repo.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// The new email is saved already; a failed token is logged like on registration
+	if emailChanged {
+		if err := s.sendVerification(ctx, user); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// ChangePassword sets a new password after checking the current one.
+// Every other session of the user is revoked; the session making the change stays logged in.
+func (s *service) ChangePassword(ctx context.Context, userID, sessionID string, req *ChangePasswordRequest) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := checkPassword(user, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.UpdatePassword(ctx, user.ID, string(hashedPassword), sessionID)
+}
+
+// DeleteAccount permanently deletes a user and all their data after checking their password
+func (s *service) DeleteAccount(ctx context.Context, userID string, req *DeleteAccountRequest) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := checkPassword(user, req.Password); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, user.ID)
+}
+
+// checkPassword returns ErrIncorrectPassword unless password is the password of the user
+func checkPassword(user *User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token.
+// The presented token can be used only once: presenting it again revokes its session,
+// logging out both the legitimate client and whoever copied the token.
+func (s *service) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*AuthResponse, error) {
+	current, err := s.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if current.UsedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, current)
+	}
+
+	user, err := s.repo.FindByID(ctx, current.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if s.blocksUnverified(user) {
+		return nil, ErrEmailNotVerified
+	}
+
+	next, value, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RotateRefreshToken(ctx, current, next, client, s.refreshTTL); err != nil {
+		if err == ErrRefreshTokenReused {
+			// Another request rotated the token first
+			return nil, s.revokeReusedFamily(ctx, current)
+		}
+		return nil, err
+	}
+
+	return s.authResponse(user, current.FamilyID, value)
+}
+
+// Logout revokes the session of a refresh token. Unknown tokens are ignored.
+func (s *service) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.findRefreshToken(ctx, refreshToken)
+	if err != nil || token == nil {
+		return err
+	}
+	return s.repo.RevokeSession(ctx, token.FamilyID)
+}
+
+// GetSessions retrieves the active sessions of a user, flagging the one making the request
+func (s *service) GetSessions(ctx context.Context, userID, currentSessionID string) ([]Session, error) {
+	sessions, err := s.repo.FindSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Device = describeDevice(sessions[i].UserAgent)
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of the user. Its access tokens are rejected from now on
+// and its refresh token can no longer be used.
+func (s *service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.repo.FindSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// Sessions of other users are reported as missing so their IDs cannot be probed
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	return s.repo.RevokeSession(ctx, session.ID)
+}
+
+// CheckSession reports whether a session of the user is still active.
+// It implements middleware.SessionChecker.
+func (s *service) CheckSession(ctx context.Context, userID, sessionID string) (middleware.SessionAccess, error) {
+	active, verified, err := s.repo.TouchSession(ctx, userID, sessionID)
+	if err != nil || !active {
+		return middleware.AccessRevoked, err
+	}
+
+	if !verified {
+		switch s.verificationMode {
+		case VerificationBlocked:
+			return middleware.AccessUnverified, nil
+		case VerificationReadOnly:
+			return middleware.AccessReadOnly, nil
+		}
+	}
+	return middleware.AccessFull, nil
+}
+
+// PurgeExpiredSessions deletes expired sessions and expired refresh, password reset and email verification tokens
+// and returns how many sessions were deleted
+func (s *service) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	purged, err := s.repo.DeleteExpiredSessions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Rotated tokens of sessions that are still active
+	if _, err := s.repo.DeleteExpiredRefreshTokens(ctx); err != nil {
+		return 0, err
+	}
+	if _, err := s.repo.DeleteExpiredPasswordResetTokens(ctx); err != nil {
+		return 0, err
+	}
+	if _, err := s.repo.DeleteExpiredEmailVerificationTokens(ctx); err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// RunSessionPurge purges expired sessions every interval until ctx is done
+func RunSessionPurge(ctx context.Context, service Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := service.PurgeExpiredSessions(ctx)
+		if err != nil {
+			log.Printf("Failed to purge expired sessions: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d expired sessions", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startSession creates a login session for the user and returns its first refresh token with an access token
+func (s *service) startSession(ctx context.Context, user *User, client ClientInfo) (*AuthResponse, error) {
+	token, value, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+	}
+	if err := s.repo.CreateSession(ctx, session, token, s.refreshTTL); err != nil {
+		return nil, err
+	}
+
+	return s.authResponse(user, session.ID, value)
+}
+
+// authResponse builds the response for a user of a session holding the refresh token value
+func (s *service) authResponse(user *User, sessionID, refreshToken string) (*AuthResponse, error) {
+	token, err := middleware.GenerateToken(user.ID, user.Email, sessionID, s.jwtSecret, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		Token:            token,
+		ExpiresIn:        int(s.accessTTL / time.Second),
+		RefreshToken:     refreshToken,
+		RefreshExpiresIn: int(s.refreshTTL / time.Second),
+		User:             *user,
+	}, nil
+}
+
+// findRefreshToken looks up an unexpired refresh token by its value
+func (s *service) findRefreshToken(ctx context.Context, value string) (*RefreshToken, error) {
+	if value == "" {
+		return nil, nil
+	}
+	return s.repo.FindRefreshTokenByHash(ctx, hashToken(value))
+}
+
+// revokeReusedFamily revokes the session of a token presented after rotation and returns ErrRefreshTokenReused
+func (s *service) revokeReusedFamily(ctx context.Context, token *RefreshToken) error {
+	log.Printf("Refresh token reuse detected for user %s, revoking session %s", token.UserID, token.FamilyID)
+	if err := s.repo.RevokeSession(ctx, token.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// ForgotPassword emails a password reset link to the user with the given email.
+// Unknown emails are ignored so the response does not reveal which accounts exist,
+// and a user gets at most one email per passwordResetWindow.
+func (s *service) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
+	user, err := s.repo.FindByEmail(ctx, req.Email)
+	if err != nil || user == nil {
+		return err
+	}
+
+	recent, err := s.repo.CountRecentPasswordResetTokens(ctx, user.ID, passwordResetWindow)
+	if err != nil || recent > 0 {
+		return err
+	}
+
+	value, hash, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreatePasswordResetToken(ctx, user.ID, hash, s.resetTTL); err != nil {
+		return err
+	}
+
+	msg := passwordResetMessage(user, s.link("/reset-password", value), s.resetTTL)
+	s.sendMail(ctx, msg)
+	return nil
+}
+
+// ResetPassword sets a new password using a password reset token.
+// The token is consumed and every session of the user is revoked.
+func (s *service) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.ResetPassword(ctx, hashToken(req.Token), string(hashedPassword))
+}
+
+// VerifyEmail marks the email of a user as verified using the token sent to it
+func (s *service) VerifyEmail(ctx context.Context, req *VerifyEmailRequest) error {
+	return s.repo.VerifyEmail(ctx, hashToken(req.Token))
+}
+
+// ResendVerification emails a new verification link to the user with the given email.
+// Unknown and already verified emails are ignored, and a user gets at most one email
+// per verificationResendWindow.
+func (s *service) ResendVerification(ctx context.Context, req *ResendVerificationRequest) error {
+	user, err := s.repo.FindByEmail(ctx, req.Email)
+	if err != nil || user == nil || user.EmailVerified() {
+		return err
+	}
+
+	recent, err := s.repo.CountRecentEmailVerificationTokens(ctx, user.ID, verificationResendWindow)
+	if err != nil || recent > 0 {
+		return err
+	}
+
+	return s.sendVerification(ctx, user)
+}
+
+// sendVerification emails a link verifying the current email of the user
+func (s *service) sendVerification(ctx context.Context, user *User) error {
+	value, hash, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateEmailVerificationToken(ctx, user.ID, user.Email, hash, s.verificationTTL); err != nil {
+		return err
+	}
+
+	s.sendMail(ctx, emailVerificationMessage(user, s.link("/verify-email", value), s.verificationTTL))
+	return nil
+}
+
+// blocksUnverified reports whether the user may not log in until their email is verified
+func (s *service) blocksUnverified(user *User) bool {
+	return s.verificationMode == VerificationBlocked && !user.EmailVerified()
+}
+
+// link builds a frontend URL carrying a token
+func (s *service) link(path, token string) string {
+	return strings.TrimRight(s.frontendURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendMail delivers an email in the background, logging failures. Callers do not wait for
+// delivery, so neither its errors nor its duration reveal whether an account exists.
+func (s *service) sendMail(ctx context.Context, msg *mailer.Message) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mailTimeout)
+	go func() {
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send %q email: %v", msg.Subject, err)
+		}
+	}()
+}
+
+// newRefreshToken generates a random refresh token value and the token holding its hash
+func newRefreshToken() (*RefreshToken, string, error) {
+	value, hash, err := newSecretToken()
+	if err != nil {
+		return nil, "", err
+	}
+	return &RefreshToken{TokenHash: hash}, value, nil
+}
+
+// newSecretToken generates a random token value and the hash under which it is stored
+func newSecretToken() (string, string, error) {
+	buf := make([]byte, secretTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(buf)
+	return value, hashToken(value), nil
+}
+
+// hashToken returns the hex SHA-256 hash under which a secret token is stored
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,406 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"vocabulary-app-be/pkg/config"
+	"vocabulary-app-be/pkg/middleware"
+)
+
+// newTestService creates a service over a fake repository and a recording mailer
+func newTestService(mode VerificationMode) (*service, *fakeRepository, *recordingMailer) {
+	repo := newFakeRepository()
+	mail := newRecordingMailer()
+	cfg := &config.Config{
+		JWTSecret:             "test-secret",
+		FrontendURL:           "https://app.example.com/",
+		EmailVerificationMode: string(mode),
+	}
+	return NewService(repo, mail, cfg).(*service), repo, mail
+}
+
+// register registers a user and drains the verification email sent to them
+func register(t *testing.T, s *service, mail *recordingMailer, email string) *AuthResponse {
+	t.Helper()
+	resp, err := s.Register(context.Background(), &RegisterRequest{Email: email, Password: "secret1", Name: "Test"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	mail.wait(t)
+	return resp
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	ctx := context.Background()
+	s, _, mail := newTestService(VerificationOff)
+	login := register(t, s, mail, "ann@example.com")
+
+	refreshed, err := s.Refresh(ctx, login.RefreshToken, ClientInfo{UserAgent: "curl/8.0"})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if refreshed.RefreshToken == login.RefreshToken {
+		t.Error("Refresh() returned the presented refresh token again")
+	}
+	if refreshed.Token == "" {
+		t.Error("Refresh() returned no access token")
+	}
+
+	if _, err := s.Refresh(ctx, refreshed.RefreshToken, ClientInfo{}); err != nil {
+		t.Errorf("Refresh() with the rotated token error = %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	s, repo, mail := newTestService(VerificationOff)
+	login := register(t, s, mail, "ann@example.com")
+
+	refreshed, err := s.Refresh(ctx, login.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	if _, err := s.Refresh(ctx, login.RefreshToken, ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh() with a used token error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if _, err := s.Refresh(ctx, refreshed.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() after reuse error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	sessions, _ := repo.FindSessionsByUserID(ctx, login.User.ID)
+	if len(sessions) != 0 {
+		t.Errorf("%d sessions are active after reuse, want 0", len(sessions))
+	}
+}
+
+func TestRefreshConcurrentRotation(t *testing.T) {
+	ctx := context.Background()
+	s, repo, mail := newTestService(VerificationOff)
+	login := register(t, s, mail, "ann@example.com")
+
+	repo.rotateRace = true
+	if _, err := s.Refresh(ctx, login.RefreshToken, ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh() losing the race error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	sessions, _ := repo.FindSessionsByUserID(ctx, login.User.ID)
+	if len(sessions) != 0 {
+		t.Errorf("%d sessions are active after a concurrent rotation, want 0", len(sessions))
+	}
+}
+
+func TestRefreshUnknownToken(t *testing.T) {
+	s, _, _ := newTestService(VerificationOff)
+
+	for _, token := range []string{"", "not-a-token"} {
+		if _, err := s.Refresh(context.Background(), token, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("Refresh(%q) error = %v, want %v", token, err, ErrInvalidRefreshToken)
+		}
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	s, _, mail := newTestService(VerificationOff)
+	login := register(t, s, mail, "ann@example.com")
+
+	if err := s.Logout(ctx, login.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := s.Refresh(ctx, login.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() after logout error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if err := s.Logout(ctx, "unknown"); err != nil {
+		t.Errorf("Logout() with an unknown token error = %v", err)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	ctx := context.Background()
+	s, repo, mail := newTestService(VerificationOff)
+	ann := register(t, s, mail, "ann@example.com")
+	bob := register(t, s, mail, "bob@example.com")
+
+	phone, err := s.Login(ctx, &LoginRequest{Email: "ann@example.com", Password: "secret1"}, ClientInfo{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	sessions, err := s.GetSessions(ctx, ann.User.ID, sessionID(t, repo, ann))
+	if err != nil {
+		t.Fatalf("GetSessions() error = %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("GetSessions() returned %d sessions, want 2", len(sessions))
+	}
+	current := 0
+	for _, session := range sessions {
+		if session.Current {
+			current++
+		}
+	}
+	if current != 1 {
+		t.Errorf("GetSessions() flagged %d sessions as current, want 1", current)
+	}
+
+	phoneID := sessionID(t, repo, phone)
+	if err := s.RevokeSession(ctx, bob.User.ID, phoneID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("RevokeSession() of another user's session error = %v, want %v", err, ErrSessionNotFound)
+	}
+	if err := s.RevokeSession(ctx, ann.User.ID, phoneID); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if err := s.RevokeSession(ctx, ann.User.ID, phoneID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("RevokeSession() twice error = %v, want %v", err, ErrSessionNotFound)
+	}
+
+	if access, _ := s.CheckSession(ctx, ann.User.ID, phoneID); access != middleware.AccessRevoked {
+		t.Errorf("CheckSession() of a revoked session = %v, want %v", access, middleware.AccessRevoked)
+	}
+	if _, err := s.Refresh(ctx, phone.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() of a revoked session error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if access, _ := s.CheckSession(ctx, ann.User.ID, sessionID(t, repo, ann)); access != middleware.AccessFull {
+		t.Errorf("CheckSession() of the other session = %v, want %v", access, middleware.AccessFull)
+	}
+}
+
+// sessionID returns the session of the refresh token in an auth response
+func sessionID(t *testing.T, repo *fakeRepository, resp *AuthResponse) string {
+	t.Helper()
+	token, err := repo.FindRefreshTokenByHash(context.Background(), hashToken(resp.RefreshToken))
+	if err != nil || token == nil {
+		t.Fatalf("refresh token of the response not found: %v", err)
+	}
+	return token.FamilyID
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	s, _, mail := newTestService(VerificationOff)
+	login := register(t, s, mail, "ann@example.com")
+
+	if err := s.ForgotPassword(ctx, &ForgotPasswordRequest{Email: "ann@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	msg := mail.wait(t)
+	if msg.To != "ann@example.com" {
+		t.Errorf("reset email sent to %q, want ann@example.com", msg.To)
+	}
+	token := mailToken(t, msg)
+
+	if err := s.ResetPassword(ctx, &ResetPasswordRequest{Token: token, Password: "secret2"}); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if err := s.ResetPassword(ctx, &ResetPasswordRequest{Token: token, Password: "secret3"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("ResetPassword() with a used token error = %v, want %v", err, ErrInvalidResetToken)
+	}
+
+	if _, err := s.Refresh(ctx, login.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() after a reset error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if _, err := s.Login(ctx, &LoginRequest{Email: "ann@example.com", Password: "secret1"}, ClientInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login() with the old password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := s.Login(ctx, &LoginRequest{Email: "ann@example.com", Password: "secret2"}, ClientInfo{}); err != nil {
+		t.Errorf("Login() with the new password error = %v", err)
+	}
+}
+
+func TestForgotPasswordThrottled(t *testing.T) {
+	ctx := context.Background()
+	s, _, mail := newTestService(VerificationOff)
+	register(t, s, mail, "ann@example.com")
+
+	if err := s.ForgotPassword(ctx, &ForgotPasswordRequest{Email: "ann@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	mail.wait(t)
+
+	if err := s.ForgotPassword(ctx, &ForgotPasswordRequest{Email: "ann@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() again error = %v", err)
+	}
+	mail.expectNone(t)
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	s, _, mail := newTestService(VerificationOff)
+
+	if err := s.ForgotPassword(context.Background(), &ForgotPasswordRequest{Email: "nobody@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	mail.expectNone(t)
+}
+
+func TestVerificationBlocksLogin(t *testing.T) {
+	ctx := context.Background()
+	s, _, mail := newTestService(VerificationBlocked)
+
+	resp, err := s.Register(ctx, &RegisterRequest{Email: "ann@example.com", Password: "secret1", Name: "Ann"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if resp.Token != "" || resp.RefreshToken != "" {
+		t.Error("Register() started a session for an unverified account")
+	}
+	token := mailToken(t, mail.wait(t))
+
+	login := &LoginRequest{Email: "ann@example.com", Password: "secret1"}
+	if _, err := s.Login(ctx, login, ClientInfo{}); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("Login() before verification error = %v, want %v", err, ErrEmailNotVerified)
+	}
+
+	if err := s.VerifyEmail(ctx, &VerifyEmailRequest{Token: token}); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if err := s.VerifyEmail(ctx, &VerifyEmailRequest{Token: token}); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Errorf("VerifyEmail() with a used token error = %v, want %v", err, ErrInvalidVerificationToken)
+	}
+	if _, err := s.Login(ctx, login, ClientInfo{}); err != nil {
+		t.Errorf("Login() after verification error = %v", err)
+	}
+}
+
+func TestVerificationModeAccess(t *testing.T) {
+	tests := []struct {
+		mode VerificationMode
+		want middleware.SessionAccess
+	}{
+		{mode: VerificationOff, want: middleware.AccessFull},
+		{mode: VerificationReadOnly, want: middleware.AccessReadOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			ctx := context.Background()
+			s, repo, mail := newTestService(tt.mode)
+			login := register(t, s, mail, "ann@example.com")
+
+			access, err := s.CheckSession(ctx, login.User.ID, sessionID(t, repo, login))
+			if err != nil {
+				t.Fatalf("CheckSession() error = %v", err)
+			}
+			if access != tt.want {
+				t.Errorf("CheckSession() of an unverified user = %v, want %v", access, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterSurvivesFailedVerification(t *testing.T) {
+	s, repo, mail := newTestService(VerificationReadOnly)
+	repo.verificationErr = errors.New("database unavailable")
+
+	resp, err := s.Register(context.Background(), &RegisterRequest{Email: "ann@example.com", Password: "secret1", Name: "Ann"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if resp.Token == "" {
+		t.Error("Register() started no session")
+	}
+	mail.expectNone(t)
+}
+
+func TestResendVerification(t *testing.T) {
+	ctx := context.Background()
+	s, _, mail := newTestService(VerificationReadOnly)
+	register(t, s, mail, "ann@example.com")
+	req := &ResendVerificationRequest{Email: "ann@example.com"}
+
+	// The registration email was sent within the resend window
+	if err := s.ResendVerification(ctx, req); err != nil {
+		t.Fatalf("ResendVerification() error = %v", err)
+	}
+	mail.expectNone(t)
+
+	if err := s.ResendVerification(ctx, &ResendVerificationRequest{Email: "nobody@example.com"}); err != nil {
+		t.Fatalf("ResendVerification() of an unknown email error = %v", err)
+	}
+	mail.expectNone(t)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	s, _, mail := newTestService(VerificationReadOnly)
+	ann := register(t, s, mail, "ann@example.com")
+	register(t, s, mail, "bob@example.com")
+	userID := ann.User.ID
+
+	blank, newEmail, taken := "  ", "ann@example.org", "bob@example.com"
+	errorTests := []struct {
+		name string
+		req  UpdateProfileRequest
+		want error
+	}{
+		{name: "blank name", req: UpdateProfileRequest{Name: &blank}, want: ErrInvalidName},
+		{name: "email without password", req: UpdateProfileRequest{Email: &newEmail}, want: ErrPasswordRequired},
+		{name: "email with wrong password", req: UpdateProfileRequest{Email: &newEmail, CurrentPassword: "wrong"}, want: ErrIncorrectPassword},
+		{name: "email taken", req: UpdateProfileRequest{Email: &taken, CurrentPassword: "secret1"}, want: ErrUserAlreadyExists},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.UpdateProfile(ctx, userID, &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("UpdateProfile() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	mail.expectNone(t)
+
+	user, err := s.UpdateProfile(ctx, userID, &UpdateProfileRequest{Email: &newEmail, CurrentPassword: "secret1"})
+	if err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+	if user.Email != newEmail || user.EmailVerified() {
+		t.Errorf("UpdateProfile() = %s verified %v, want unverified %s", user.Email, user.EmailVerified(), newEmail)
+	}
+	msg := mail.wait(t)
+	if msg.To != newEmail {
+		t.Errorf("verification email sent to %q, want %q", msg.To, newEmail)
+	}
+	if err := s.VerifyEmail(ctx, &VerifyEmailRequest{Token: mailToken(t, msg)}); err != nil {
+		t.Errorf("VerifyEmail() of the new email error = %v", err)
+	}
+}
+
+func TestChangePasswordKeepsCurrentSession(t *testing.T) {
+	ctx := context.Background()
+	s, repo, mail := newTestService(VerificationOff)
+	current := register(t, s, mail, "ann@example.com")
+	other, err := s.Login(ctx, &LoginRequest{Email: "ann@example.com", Password: "secret1"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	userID, currentID := current.User.ID, sessionID(t, repo, current)
+
+	if err := s.ChangePassword(ctx, userID, currentID, &ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "secret2"}); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("ChangePassword() with a wrong password error = %v, want %v", err, ErrIncorrectPassword)
+	}
+	if err := s.ChangePassword(ctx, userID, currentID, &ChangePasswordRequest{CurrentPassword: "secret1", NewPassword: "secret2"}); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+
+	if _, err := s.Refresh(ctx, current.RefreshToken, ClientInfo{}); err != nil {
+		t.Errorf("Refresh() of the current session error = %v", err)
+	}
+	if _, err := s.Refresh(ctx, other.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() of another session error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	ctx := context.Background()
+	s, _, mail := newTestService(VerificationOff)
+	userID := register(t, s, mail, "ann@example.com").User.ID
+
+	if err := s.DeleteAccount(ctx, userID, &DeleteAccountRequest{Password: "wrong"}); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("DeleteAccount() with a wrong password error = %v, want %v", err, ErrIncorrectPassword)
+	}
+	if err := s.DeleteAccount(ctx, userID, &DeleteAccountRequest{Password: "secret1"}); err != nil {
+		t.Fatalf("DeleteAccount() error = %v", err)
+	}
+	if _, err := s.GetUserByID(ctx, userID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetUserByID() after deletion error = %v, want %v", err, ErrUserNotFound)
+	}
+}
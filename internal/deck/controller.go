@@ -0,0 +1,245 @@
+package deck
+
+import (
+	"context"
+	"net/http"
+
+	"vocabulary-app-be/pkg/middleware"
+	"vocabulary-app-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Controller handles HTTP requests for decks
+type Controller struct {
+	service Service
+}
+
+// NewController creates a new deck controller
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
+
+// RegisterRoutes registers deck routes
+func RegisterRoutes(router *gin.Engine, c *Controller, jwtSecret string, loginSessions middleware.SessionChecker) {
+	decks := router.Group("/api/decks")
+	// Add auth middleware
+	decks.Use(middleware.AuthMiddleware(jwtSecret, loginSessions))
+	{
+		decks.POST("", c.Create)
+		decks.GET("", c.GetAll)
+		decks.GET("/:id", c.GetByID)
+		decks.PUT("/:id", c.Update)
+		decks.DELETE("/:id", c.Delete)
+		decks.POST("/:id/vocabularies", c.AddVocabularies)
+		decks.DELETE("/:id/vocabularies", c.RemoveVocabularies)
+	}
+}
+
+// getUserID extracts user ID from context (set by auth middleware)
+func getUserID(ctx *gin.Context) string {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		return ""
+	}
+	return userID.(string)
+}
+
+// Create handles deck creation
+func (c *Controller) Create(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateDeckRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deck, err := c.service.Create(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidName:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid deck name")
+		case ErrDeckNameTaken:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Deck name already exists")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create deck")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "Deck created successfully", deck)
+}
+
+// GetAll handles getting all decks for a user
+func (c *Controller) GetAll(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	decks, err := c.service.GetByUserID(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get decks")
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Decks retrieved successfully", decks)
+}
+
+// GetByID handles getting a deck by ID
+func (c *Controller) GetByID(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	deck, err := c.service.GetByID(ctx.Request.Context(), userID, id)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrDeckNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Deck not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get deck")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Deck retrieved successfully", deck)
+}
+
+// Update handles deck update
+func (c *Controller) Update(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req UpdateDeckRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deck, err := c.service.Update(ctx.Request.Context(), userID, id, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrDeckNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Deck not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrDeckNameTaken:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Deck name already exists")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update deck")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Deck updated successfully", deck)
+}
+
+// Delete handles deck deletion
+func (c *Controller) Delete(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), userID, id); err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrDeckNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Deck not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete deck")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusNoContent, "Deck deleted successfully", nil)
+}
+
+// AddVocabularies handles attaching vocabularies to a deck
+func (c *Controller) AddVocabularies(ctx *gin.Context) {
+	c.changeVocabularies(ctx, c.service.AddVocabularies, "Vocabularies added to deck successfully")
+}
+
+// RemoveVocabularies handles detaching vocabularies from a deck
+func (c *Controller) RemoveVocabularies(ctx *gin.Context) {
+	c.changeVocabularies(ctx, c.service.RemoveVocabularies, "Vocabularies removed from deck successfully")
+}
+
+// changeVocabularies binds a DeckVocabulariesRequest and applies change to the deck
+func (c *Controller) changeVocabularies(ctx *gin.Context, change func(context.Context, string, string, *DeckVocabulariesRequest) (*DeckVocabulariesResponse, error), message string) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req DeckVocabulariesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := change(ctx.Request.Context(), userID, id, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrDeckNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Deck not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update deck vocabularies")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, message, response)
+}
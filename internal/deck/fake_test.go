@@ -0,0 +1,189 @@
+package deck
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"vocabulary-app-be/internal/testutil"
+	"vocabulary-app-be/internal/vocab"
+
+	"github.com/lib/pq"
+)
+
+// fakeRepository is an in-memory Repository with the semantics of the SQL repository.
+// Deck memberships are kept by fakeVocabService, which counts the vocabularies of a deck.
+type fakeRepository struct {
+	mu     sync.Mutex
+	ids    testutil.Sequence
+	decks  *testutil.Table[Deck]
+	vocabs *fakeVocabService
+}
+
+func newFakeRepository(vocabs *fakeVocabService) *fakeRepository {
+	r := &fakeRepository{decks: testutil.NewTable[Deck](), vocabs: vocabs}
+	vocabs.repo = r
+	return r
+}
+
+func (r *fakeRepository) Create(ctx context.Context, deck *Deck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.taken(deck) {
+		return ErrDeckNameTaken
+	}
+	deck.ID = r.ids.Next()
+	deck.CreatedAt = time.Now()
+	deck.UpdatedAt = deck.CreatedAt
+	r.decks.Insert(deck.ID, *deck)
+	return nil
+}
+
+// taken reports whether another deck of the owner of deck has its name
+func (r *fakeRepository) taken(deck *Deck) bool {
+	return r.decks.Find(func(other *Deck) bool {
+		return other.ID != deck.ID && other.UserID == deck.UserID && strings.EqualFold(other.Name, deck.Name)
+	}) != nil
+}
+
+func (r *fakeRepository) FindByID(ctx context.Context, id string) (*Deck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deck := r.decks.Get(id)
+	if deck != nil {
+		deck.VocabularyCount = r.vocabs.count(id)
+	}
+	return deck, nil
+}
+
+func (r *fakeRepository) FindByUserID(ctx context.Context, userID string) ([]Deck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Deck{}, r.decks.Filter(func(deck *Deck) bool { return deck.UserID == userID })...), nil
+}
+
+func (r *fakeRepository) FindByUserIDAndName(ctx context.Context, userID, name string) (*Deck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.decks.Find(func(deck *Deck) bool {
+		return deck.UserID == userID && strings.EqualFold(deck.Name, name)
+	}), nil
+}
+
+func (r *fakeRepository) Update(ctx context.Context, deck *Deck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.taken(deck) {
+		return ErrDeckNameTaken
+	}
+	stored := r.decks.Row(deck.ID)
+	stored.Name, stored.Description, stored.UpdatedAt = deck.Name, deck.Description, time.Now()
+	deck.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+// fakeVocabService keeps the decks of vocabularies in memory; methods decks do not use are left
+// to the embedded nil Service. Every change of the tags of a vocabulary is counted as a revision.
+type fakeVocabService struct {
+	vocab.Service
+
+	mu   sync.Mutex
+	repo *fakeRepository
+	// owners maps vocabulary IDs to their user ID
+	owners map[string]string
+	// members maps deck IDs to the IDs of their vocabularies
+	members   map[string]map[string]bool
+	revisions map[string]int
+
+	// renameErr is returned by RenameDeck when set, before anything is renamed
+	renameErr error
+}
+
+func newFakeVocabService() *fakeVocabService {
+	return &fakeVocabService{
+		owners:    map[string]string{},
+		members:   map[string]map[string]bool{},
+		revisions: map[string]int{},
+	}
+}
+
+// count returns the number of vocabularies in a deck
+func (s *fakeVocabService) count(deckID string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.members[deckID]))
+}
+
+// deck returns the deck of a user with the given ID, locking the repository while fn runs
+func (s *fakeVocabService) deck(userID, deckID string, fn func(deck *Deck) error) error {
+	s.repo.mu.Lock()
+	defer s.repo.mu.Unlock()
+	deck := s.repo.decks.Row(deckID)
+	if deck == nil || deck.UserID != userID {
+		return vocab.ErrDeckNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(deck)
+}
+
+func (s *fakeVocabService) AddToDeck(ctx context.Context, userID, deckID string, ids []string) (int64, error) {
+	var changed int64
+	err := s.deck(userID, deckID, func(deck *Deck) error {
+		if s.members[deckID] == nil {
+			s.members[deckID] = map[string]bool{}
+		}
+		for _, id := range ids {
+			if s.owners[id] == userID && !s.members[deckID][id] {
+				s.members[deckID][id] = true
+				s.revisions[id]++
+				changed++
+			}
+		}
+		return nil
+	})
+	return changed, err
+}
+
+func (s *fakeVocabService) RemoveFromDeck(ctx context.Context, userID, deckID string, ids []string) (int64, error) {
+	var changed int64
+	err := s.deck(userID, deckID, func(deck *Deck) error {
+		for _, id := range ids {
+			if s.members[deckID][id] {
+				delete(s.members[deckID], id)
+				s.revisions[id]++
+				changed++
+			}
+		}
+		return nil
+	})
+	return changed, err
+}
+
+func (s *fakeVocabService) RenameDeck(ctx context.Context, userID, deckID, name string) error {
+	return s.deck(userID, deckID, func(deck *Deck) error {
+		if s.renameErr != nil {
+			return s.renameErr
+		}
+		deck.Name = name
+		for id := range s.members[deckID] {
+			s.revisions[id]++
+		}
+		return nil
+	})
+}
+
+func (s *fakeVocabService) DeleteDeck(ctx context.Context, userID, deckID string) error {
+	return s.deck(userID, deckID, func(deck *Deck) error {
+		s.repo.decks.Delete(deckID)
+		for id := range s.members[deckID] {
+			s.revisions[id]++
+		}
+		delete(s.members, deckID)
+		return nil
+	})
+}
+
+// uniqueViolation is the error Postgres returns when a unique index is violated
+var uniqueViolation = &pq.Error{Code: "23505"}
@@ -0,0 +1,37 @@
+package deck
+
+import "time"
+
+// Deck represents a user-defined deck (or tag) grouping vocabularies
+type Deck struct {
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	VocabularyCount int64     `json:"vocabulary_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CreateDeckRequest represents the create deck request payload
+type CreateDeckRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// UpdateDeckRequest represents the update deck request payload
+type UpdateDeckRequest struct {
+	Name        string  `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description"`
+}
+
+// DeckVocabulariesRequest represents the vocabularies to attach to or detach from a deck
+type DeckVocabulariesRequest struct {
+	VocabularyIDs []string `json:"vocabulary_ids" binding:"required,min=1,max=500,dive,required"`
+}
+
+// DeckVocabulariesResponse reports how many vocabularies were attached or detached
+type DeckVocabulariesResponse struct {
+	Affected int64 `json:"affected"`
+	Deck     Deck  `json:"deck"`
+}
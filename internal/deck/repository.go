@@ -0,0 +1,130 @@
+package deck
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// Repository handles data access for decks
+type Repository interface {
+	Create(ctx context.Context, deck *Deck) error
+	FindByID(ctx context.Context, id string) (*Deck, error)
+	FindByUserID(ctx context.Context, userID string) ([]Deck, error)
+	FindByUserIDAndName(ctx context.Context, userID, name string) (*Deck, error)
+	Update(ctx context.Context, deck *Deck) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new deck repository
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// deckColumns is the column list selected for a Deck, in scanDeck order.
+// Vocabularies in the trash are not counted.
+const deckColumns = `id, user_id, name, description,
+	(SELECT COUNT(*) FROM vocabulary_decks vd JOIN vocabularies v ON v.id = vd.vocabulary_id
+	 WHERE vd.deck_id = decks.id AND v.deleted_at IS NULL), created_at, updated_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanDeck scans a row selected with deckColumns into a Deck
+func scanDeck(row rowScanner, deck *Deck) error {
+	return row.Scan(
+		&deck.ID,
+		&deck.UserID,
+		&deck.Name,
+		&deck.Description,
+		&deck.VocabularyCount,
+		&deck.CreatedAt,
+		&deck.UpdatedAt,
+	)
+}
+
+// Create creates a new deck.
+// ErrDeckNameTaken is returned when another deck of the user has the name.
+func (r *repository) Create(ctx context.Context, deck *Deck) error {
+	query := `INSERT INTO decks (user_id, name, description, created_at, updated_at)
+			  VALUES ($1, $2, $3, NOW(), NOW()) RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, deck.UserID, deck.Name, deck.Description).
+		Scan(&deck.ID, &deck.CreatedAt, &deck.UpdatedAt)
+	return nameTaken(err)
+}
+
+// FindByID finds a deck by ID
+func (r *repository) FindByID(ctx context.Context, id string) (*Deck, error) {
+	query := `SELECT ` + deckColumns + ` FROM decks WHERE id = $1`
+
+	var deck Deck
+	if err := scanDeck(r.db.QueryRowContext(ctx, query, id), &deck); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &deck, nil
+}
+
+// FindByUserID finds all decks of a user ordered by name
+func (r *repository) FindByUserID(ctx context.Context, userID string) ([]Deck, error) {
+	query := `SELECT ` + deckColumns + ` FROM decks WHERE user_id = $1 ORDER BY LOWER(name) ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decks := []Deck{}
+	for rows.Next() {
+		var deck Deck
+		if err := scanDeck(rows, &deck); err != nil {
+			return nil, err
+		}
+		decks = append(decks, deck)
+	}
+
+	return decks, rows.Err()
+}
+
+// FindByUserIDAndName finds a deck of a user by name, ignoring case
+func (r *repository) FindByUserIDAndName(ctx context.Context, userID, name string) (*Deck, error) {
+	query := `SELECT ` + deckColumns + ` FROM decks WHERE user_id = $1 AND LOWER(name) = LOWER($2)`
+
+	var deck Deck
+	if err := scanDeck(r.db.QueryRowContext(ctx, query, userID, name), &deck); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &deck, nil
+}
+
+// Update updates a deck.
+// ErrDeckNameTaken is returned when another deck of the user has the name.
+func (r *repository) Update(ctx context.Context, deck *Deck) error {
+	query := `UPDATE decks SET name = $1, description = $2, updated_at = NOW() WHERE id = $3 RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, deck.Name, deck.Description, deck.ID).Scan(&deck.UpdatedAt)
+	return nameTaken(err)
+}
+
+// nameTaken maps a violation of the unique deck name index to ErrDeckNameTaken
+func nameTaken(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return ErrDeckNameTaken
+	}
+	return err
+}
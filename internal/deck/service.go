@@ -0,0 +1,170 @@
+package deck
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"vocabulary-app-be/internal/vocab"
+)
+
+var (
+	ErrDeckNotFound  = errors.New("deck not found")
+	ErrUnauthorized  = errors.New("unauthorized access")
+	ErrDeckNameTaken = errors.New("deck name already exists")
+	ErrInvalidName   = errors.New("invalid deck name")
+)
+
+// Service handles business logic for decks
+type Service interface {
+	Create(ctx context.Context, userID string, req *CreateDeckRequest) (*Deck, error)
+	GetByID(ctx context.Context, userID, id string) (*Deck, error)
+	GetByUserID(ctx context.Context, userID string) ([]Deck, error)
+	Update(ctx context.Context, userID, id string, req *UpdateDeckRequest) (*Deck, error)
+	Delete(ctx context.Context, userID, id string) error
+	AddVocabularies(ctx context.Context, userID, id string, req *DeckVocabulariesRequest) (*DeckVocabulariesResponse, error)
+	RemoveVocabularies(ctx context.Context, userID, id string, req *DeckVocabulariesRequest) (*DeckVocabulariesResponse, error)
+}
+
+type service struct {
+	repo         Repository
+	vocabService vocab.Service
+}
+
+// NewService creates a new deck service. Changes to the decks of vocabularies go through
+// vocabService, which records them in the revisions of the vocabularies.
+func NewService(repo Repository, vocabService vocab.Service) Service {
+	return &service{repo: repo, vocabService: vocabService}
+}
+
+// Create creates a new deck
+func (s *service) Create(ctx context.Context, userID string, req *CreateDeckRequest) (*Deck, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+
+	existing, err := s.repo.FindByUserIDAndName(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrDeckNameTaken
+	}
+
+	deck := &Deck{
+		UserID:      userID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	if err := s.repo.Create(ctx, deck); err != nil {
+		return nil, err
+	}
+
+	return deck, nil
+}
+
+// GetByID retrieves a deck by ID
+func (s *service) GetByID(ctx context.Context, userID, id string) (*Deck, error) {
+	deck, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deck == nil {
+		return nil, ErrDeckNotFound
+	}
+
+	// Check ownership
+	if deck.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	return deck, nil
+}
+
+// GetByUserID retrieves all decks of a user
+func (s *service) GetByUserID(ctx context.Context, userID string) ([]Deck, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+// Update updates a deck
+func (s *service) Update(ctx context.Context, userID, id string, req *UpdateDeckRequest) (*Deck, error) {
+	deck, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields
+	if name := strings.TrimSpace(req.Name); name != "" && name != deck.Name {
+		existing, err := s.repo.FindByUserIDAndName(ctx, userID, name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != deck.ID {
+			return nil, ErrDeckNameTaken
+		}
+		if err := s.vocabService.RenameDeck(ctx, userID, deck.ID, name); err != nil {
+			return nil, deckError(nameTaken(err))
+		}
+		deck.Name = name
+	}
+	if req.Description != nil {
+		deck.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if err := s.repo.Update(ctx, deck); err != nil {
+		return nil, err
+	}
+
+	return deck, nil
+}
+
+// Delete deletes a deck without deleting its vocabularies
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	if _, err := s.GetByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return deckError(s.vocabService.DeleteDeck(ctx, userID, id))
+}
+
+// AddVocabularies attaches vocabularies to a deck. IDs of vocabularies the user does not own are ignored.
+func (s *service) AddVocabularies(ctx context.Context, userID, id string, req *DeckVocabulariesRequest) (*DeckVocabulariesResponse, error) {
+	deck, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.vocabService.AddToDeck(ctx, userID, deck.ID, req.VocabularyIDs)
+	if err != nil {
+		return nil, deckError(err)
+	}
+	deck.VocabularyCount += affected
+
+	return &DeckVocabulariesResponse{Affected: affected, Deck: *deck}, nil
+}
+
+// RemoveVocabularies detaches vocabularies from a deck. Vocabularies in the trash stay in the deck.
+func (s *service) RemoveVocabularies(ctx context.Context, userID, id string, req *DeckVocabulariesRequest) (*DeckVocabulariesResponse, error) {
+	deck, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.vocabService.RemoveFromDeck(ctx, userID, deck.ID, req.VocabularyIDs)
+	if err != nil {
+		return nil, deckError(err)
+	}
+	deck.VocabularyCount -= affected
+
+	return &DeckVocabulariesResponse{Affected: affected, Deck: *deck}, nil
+}
+
+// deckError maps a deck deleted meanwhile to ErrDeckNotFound
+func deckError(err error) error {
+	if err == vocab.ErrDeckNotFound {
+		return ErrDeckNotFound
+	}
+	return err
+}
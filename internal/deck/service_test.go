@@ -0,0 +1,190 @@
+package deck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"vocabulary-app-be/internal/vocab"
+)
+
+const testUserID = "user-1"
+
+// newTestService creates a service over fakes with vocabularies owned by each user
+func newTestService(owners map[string]string) (Service, *fakeVocabService) {
+	vocabs := newFakeVocabService()
+	for id, userID := range owners {
+		vocabs.owners[id] = userID
+	}
+	return NewService(newFakeRepository(vocabs), vocabs), vocabs
+}
+
+// createDeck creates a deck of testUserID
+func createDeck(t *testing.T, s Service, name string) *Deck {
+	t.Helper()
+	deck, err := s.Create(context.Background(), testUserID, &CreateDeckRequest{Name: name})
+	if err != nil {
+		t.Fatalf("Create(%q) error = %v", name, err)
+	}
+	return deck
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(nil)
+
+	deck := createDeck(t, s, "  Chapter 3 ")
+	if deck.Name != "Chapter 3" {
+		t.Errorf("Create() name = %q, want it trimmed", deck.Name)
+	}
+	if _, err := s.Create(ctx, testUserID, &CreateDeckRequest{Name: "chapter 3"}); !errors.Is(err, ErrDeckNameTaken) {
+		t.Errorf("Create() with a taken name error = %v, want %v", err, ErrDeckNameTaken)
+	}
+	if _, err := s.Create(ctx, testUserID, &CreateDeckRequest{Name: "   "}); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Create() with a blank name error = %v, want %v", err, ErrInvalidName)
+	}
+	if _, err := s.Create(ctx, "user-2", &CreateDeckRequest{Name: "Chapter 3"}); err != nil {
+		t.Errorf("Create() with another user's deck name error = %v", err)
+	}
+}
+
+func TestUpdateRenamesTags(t *testing.T) {
+	ctx := context.Background()
+	s, vocabs := newTestService(map[string]string{"cat": testUserID})
+	deck := createDeck(t, s, "Animals")
+	if _, err := s.AddVocabularies(ctx, testUserID, deck.ID, &DeckVocabulariesRequest{VocabularyIDs: []string{"cat"}}); err != nil {
+		t.Fatalf("AddVocabularies() error = %v", err)
+	}
+
+	description := "Pets"
+	updated, err := s.Update(ctx, testUserID, deck.ID, &UpdateDeckRequest{Name: "Pets", Description: &description})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.Name != "Pets" || updated.Description != "Pets" {
+		t.Errorf("Update() = %+v, want the deck renamed and described", updated)
+	}
+	if vocabs.revisions["cat"] != 2 {
+		t.Errorf("cat has %d revisions, want 2 for adding and renaming", vocabs.revisions["cat"])
+	}
+
+	// Only the description changes
+	description = "Furry pets"
+	if _, err := s.Update(ctx, testUserID, deck.ID, &UpdateDeckRequest{Description: &description}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if vocabs.revisions["cat"] != 2 {
+		t.Errorf("cat has %d revisions after a description change, want 2", vocabs.revisions["cat"])
+	}
+}
+
+func TestUpdateNameTaken(t *testing.T) {
+	ctx := context.Background()
+	s, vocabs := newTestService(nil)
+	deck := createDeck(t, s, "Animals")
+	createDeck(t, s, "Plants")
+
+	if _, err := s.Update(ctx, testUserID, deck.ID, &UpdateDeckRequest{Name: "plants"}); !errors.Is(err, ErrDeckNameTaken) {
+		t.Errorf("Update() to a taken name error = %v, want %v", err, ErrDeckNameTaken)
+	}
+
+	// The name is taken concurrently after it was checked
+	vocabs.renameErr = uniqueViolation
+	if _, err := s.Update(ctx, testUserID, deck.ID, &UpdateDeckRequest{Name: "Food"}); !errors.Is(err, ErrDeckNameTaken) {
+		t.Errorf("Update() to a name taken concurrently error = %v, want %v", err, ErrDeckNameTaken)
+	}
+
+	// The case of the deck's own name can change
+	vocabs.renameErr = nil
+	if updated, err := s.Update(ctx, testUserID, deck.ID, &UpdateDeckRequest{Name: "animals"}); err != nil || updated.Name != "animals" {
+		t.Errorf("Update() of the name's case = %+v, %v", updated, err)
+	}
+}
+
+func TestAddAndRemoveVocabularies(t *testing.T) {
+	ctx := context.Background()
+	s, vocabs := newTestService(map[string]string{"cat": testUserID, "dog": testUserID, "fox": "user-2"})
+	deck := createDeck(t, s, "Animals")
+
+	resp, err := s.AddVocabularies(ctx, testUserID, deck.ID, &DeckVocabulariesRequest{VocabularyIDs: []string{"cat", "dog", "fox", "missing"}})
+	if err != nil {
+		t.Fatalf("AddVocabularies() error = %v", err)
+	}
+	if resp.Affected != 2 || resp.Deck.VocabularyCount != 2 {
+		t.Errorf("AddVocabularies() = %+v, want the user's 2 vocabularies added", resp)
+	}
+
+	resp, err = s.AddVocabularies(ctx, testUserID, deck.ID, &DeckVocabulariesRequest{VocabularyIDs: []string{"cat"}})
+	if err != nil {
+		t.Fatalf("AddVocabularies() error = %v", err)
+	}
+	if resp.Affected != 0 || resp.Deck.VocabularyCount != 2 {
+		t.Errorf("AddVocabularies() of a member = %+v, want nothing added", resp)
+	}
+
+	resp, err = s.RemoveVocabularies(ctx, testUserID, deck.ID, &DeckVocabulariesRequest{VocabularyIDs: []string{"dog", "fox"}})
+	if err != nil {
+		t.Fatalf("RemoveVocabularies() error = %v", err)
+	}
+	if resp.Affected != 1 || resp.Deck.VocabularyCount != 1 {
+		t.Errorf("RemoveVocabularies() = %+v, want 1 vocabulary removed", resp)
+	}
+
+	if vocabs.revisions["cat"] != 1 || vocabs.revisions["dog"] != 2 || vocabs.revisions["fox"] != 0 {
+		t.Errorf("revisions = %v, want one per change of a vocabulary's decks", vocabs.revisions)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	s, vocabs := newTestService(map[string]string{"cat": testUserID})
+	deck := createDeck(t, s, "Animals")
+	if _, err := s.AddVocabularies(ctx, testUserID, deck.ID, &DeckVocabulariesRequest{VocabularyIDs: []string{"cat"}}); err != nil {
+		t.Fatalf("AddVocabularies() error = %v", err)
+	}
+
+	if err := s.Delete(ctx, testUserID, deck.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if vocabs.revisions["cat"] != 2 {
+		t.Errorf("cat has %d revisions, want 2 for adding and deleting the deck", vocabs.revisions["cat"])
+	}
+	if _, err := s.GetByID(ctx, testUserID, deck.ID); !errors.Is(err, ErrDeckNotFound) {
+		t.Errorf("GetByID() of a deleted deck error = %v, want %v", err, ErrDeckNotFound)
+	}
+}
+
+func TestDeckDeletedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	s, vocabs := newTestService(map[string]string{"cat": testUserID})
+	deck := createDeck(t, s, "Animals")
+
+	// The deck is found, then deleted before the vocabularies are changed
+	vocabs.renameErr = vocab.ErrDeckNotFound
+	if _, err := s.Update(ctx, testUserID, deck.ID, &UpdateDeckRequest{Name: "Pets"}); !errors.Is(err, ErrDeckNotFound) {
+		t.Errorf("Update() of a deck deleted meanwhile error = %v, want %v", err, ErrDeckNotFound)
+	}
+}
+
+func TestDeckOwnership(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(map[string]string{"fox": "user-2"})
+	deck := createDeck(t, s, "Animals")
+	req := &DeckVocabulariesRequest{VocabularyIDs: []string{"fox"}}
+
+	if _, err := s.GetByID(ctx, "user-2", deck.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("GetByID() by another user error = %v, want %v", err, ErrUnauthorized)
+	}
+	if _, err := s.Update(ctx, "user-2", deck.ID, &UpdateDeckRequest{Name: "Mine"}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Update() by another user error = %v, want %v", err, ErrUnauthorized)
+	}
+	if err := s.Delete(ctx, "user-2", deck.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Delete() by another user error = %v, want %v", err, ErrUnauthorized)
+	}
+	if _, err := s.AddVocabularies(ctx, "user-2", deck.ID, req); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("AddVocabularies() by another user error = %v, want %v", err, ErrUnauthorized)
+	}
+	if _, err := s.GetByID(ctx, testUserID, "missing"); !errors.Is(err, ErrDeckNotFound) {
+		t.Errorf("GetByID() of a missing deck error = %v, want %v", err, ErrDeckNotFound)
+	}
+}
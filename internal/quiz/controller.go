@@ -0,0 +1,227 @@
+package quiz
+
+import (
+	"net/http"
+
+	"vocabulary-app-be/internal/vocab"
+	"vocabulary-app-be/pkg/middleware"
+	"vocabulary-app-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Controller handles HTTP requests for quiz sessions
+type Controller struct {
+	service Service
+}
+
+// NewController creates a new quiz controller
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
+
+// RegisterRoutes registers quiz session routes
+func RegisterRoutes(router *gin.Engine, c *Controller, jwtSecret string, loginSessions middleware.SessionChecker) {
+	sessions := router.Group("/api/test/sessions")
+	// Add auth middleware
+	sessions.Use(middleware.AuthMiddleware(jwtSecret, loginSessions))
+	{
+		sessions.POST("", c.Create)
+		sessions.GET("/:id", c.GetByID)
+		sessions.GET("/:id/next", c.Next)
+		sessions.POST("/:id/answer", c.Answer)
+		sessions.POST("/:id/abandon", c.Abandon)
+	}
+}
+
+// getUserID extracts user ID from context (set by auth middleware)
+func getUserID(ctx *gin.Context) string {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		return ""
+	}
+	return userID.(string)
+}
+
+// Create handles quiz session creation
+func (c *Controller) Create(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := c.service.Create(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidStatus:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid status. Use: all, learning, or memorized")
+		case ErrInvalidDirection:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid direction. Use: forward or reverse")
+		case ErrInvalidMode:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid mode. Use: typing, definition, cloze, pronunciation, gender, part_of_speech, or form")
+		case vocab.ErrNoVocabsAvailable:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "No vocabularies available for testing")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create quiz session")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "Quiz session created successfully", response)
+}
+
+// GetByID handles getting a quiz session
+func (c *Controller) GetByID(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	response, err := c.service.GetByID(ctx.Request.Context(), userID, id)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrSessionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Quiz session not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get quiz session")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Quiz session retrieved successfully", response)
+}
+
+// Next handles getting the next question of a quiz session
+func (c *Controller) Next(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	response, err := c.service.Next(ctx.Request.Context(), userID, id)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrSessionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Quiz session not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrSessionNotActive:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Quiz session is not active")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get next question")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Next question retrieved successfully", response)
+}
+
+// Answer handles answering the current question of a quiz session
+func (c *Controller) Answer(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req AnswerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := c.service.Answer(ctx.Request.Context(), userID, id, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrSessionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Quiz session not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrSessionNotActive:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Quiz session is not active")
+		case ErrQuestionAnswered, vocab.ErrQuestionAnswered:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Question has already been answered")
+		case vocab.ErrQuestionNotFound:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Get the current question before answering")
+		case vocab.ErrQuestionExpired:
+			utils.ErrorResponse(ctx, http.StatusGone, "Question has expired; get the current question again")
+		case vocab.ErrInvalidForm:
+			utils.ErrorResponse(ctx, http.StatusConflict, "The form asked for was removed from this vocabulary")
+		case vocab.ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusConflict, "The vocabulary of this question was deleted; the question was skipped")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to submit answer")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Answer submitted successfully", response)
+}
+
+// Abandon handles abandoning a quiz session
+func (c *Controller) Abandon(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	response, err := c.service.Abandon(ctx.Request.Context(), userID, id)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrSessionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Quiz session not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrSessionNotActive:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Quiz session is not active")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to abandon quiz session")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Quiz session abandoned successfully", response)
+}
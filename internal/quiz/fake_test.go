@@ -0,0 +1,254 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"vocabulary-app-be/internal/testutil"
+	"vocabulary-app-be/internal/vocab"
+)
+
+// fakeRepository is an in-memory Repository with the semantics of the SQL repository
+type fakeRepository struct {
+	mu       sync.Mutex
+	ids      testutil.Sequence
+	sessions *testutil.Table[Session]
+	items    *testutil.Table[SessionItem]
+
+	// recordErr is returned once by RecordAnswer when set, before anything is recorded
+	recordErr error
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		sessions: testutil.NewTable[Session](),
+		items:    testutil.NewTable[SessionItem](),
+	}
+}
+
+func (r *fakeRepository) Create(ctx context.Context, session *Session, vocabIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session.ID = r.ids.Next()
+	session.QuestionCount = len(vocabIDs)
+	session.StartedAt = time.Now()
+	session.UpdatedAt = session.StartedAt
+	r.sessions.Insert(session.ID, *session)
+
+	for i, vocabID := range vocabIDs {
+		id := r.ids.Next()
+		r.items.Insert(id, SessionItem{ID: id, SessionID: session.ID, VocabularyID: vocabID, Position: i + 1})
+	}
+	return nil
+}
+
+func (r *fakeRepository) FindByID(ctx context.Context, id string) (*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessions.Get(id), nil
+}
+
+func (r *fakeRepository) FindNextItem(ctx context.Context, sessionID string) (*SessionItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	open := r.openItems(sessionID)
+	if len(open) == 0 {
+		return nil, nil
+	}
+	return &open[0], nil
+}
+
+// openItems returns the unanswered items of a session by position
+func (r *fakeRepository) openItems(sessionID string) []SessionItem {
+	open := r.items.Filter(func(item *SessionItem) bool {
+		return item.SessionID == sessionID && item.AnsweredAt == nil
+	})
+	sort.Slice(open, func(i, j int) bool { return open[i].Position < open[j].Position })
+	return open
+}
+
+func (r *fakeRepository) SetItemQuestion(ctx context.Context, item *SessionItem, questionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items.Row(item.ID).QuestionID = &questionID
+	item.QuestionID = &questionID
+	return nil
+}
+
+func (r *fakeRepository) SkipItem(ctx context.Context, session *Session, item *SessionItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.items.Row(item.ID) == nil {
+		return nil
+	}
+	r.items.Delete(item.ID)
+
+	stored := r.sessions.Row(session.ID)
+	stored.QuestionCount--
+	now := time.Now()
+	if len(r.openItems(session.ID)) == 0 {
+		stored.Status = SessionCompleted
+		stored.CompletedAt = &now
+	}
+	stored.UpdatedAt = now
+	*session = *stored
+	return nil
+}
+
+func (r *fakeRepository) RecordAnswer(ctx context.Context, session *Session, item *SessionItem, input string, passed bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.recordErr; err != nil {
+		r.recordErr = nil
+		return err
+	}
+
+	stored := r.items.Row(item.ID)
+	if stored == nil || stored.AnsweredAt != nil {
+		return ErrQuestionAnswered
+	}
+	now := time.Now()
+	stored.Input, stored.Passed, stored.AnsweredAt = &input, &passed, &now
+
+	s := r.sessions.Row(session.ID)
+	s.AnsweredCount++
+	if passed {
+		s.CorrectCount++
+	}
+	if len(r.openItems(session.ID)) == 0 {
+		s.Status = SessionCompleted
+		s.CompletedAt = &now
+	}
+	s.UpdatedAt = now
+	*session = *s
+	*item = *stored
+	return nil
+}
+
+func (r *fakeRepository) UpdateStatus(ctx context.Context, session *Session, status SessionStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.sessions.Row(session.ID)
+	now := time.Now()
+	s.Status, s.CompletedAt, s.UpdatedAt = status, &now, now
+	*session = *s
+	return nil
+}
+
+// fakeQuestion is a question issued by fakeVocabService
+type fakeQuestion struct {
+	vocabID string
+	expired bool
+	result  *vocab.TestResultResponse
+}
+
+// fakeVocabService issues and grades free-text questions in memory. The answer to a question
+// is the vocabulary ID; methods quiz sessions do not use are left to the embedded nil Service.
+type fakeVocabService struct {
+	vocab.Service
+
+	mu        sync.Mutex
+	nextID    int
+	vocabIDs  []string
+	trashed   map[string]bool
+	questions map[string]*fakeQuestion
+	// graded counts the graded answers per vocabulary, i.e. updates of its test results
+	graded map[string]int
+}
+
+func newFakeVocabService(vocabIDs ...string) *fakeVocabService {
+	return &fakeVocabService{
+		vocabIDs:  vocabIDs,
+		trashed:   map[string]bool{},
+		questions: map[string]*fakeQuestion{},
+		graded:    map[string]int{},
+	}
+}
+
+func (s *fakeVocabService) GetForTest(ctx context.Context, userID string, filter vocab.TestFilter, limit int) ([]vocab.Vocabulary, error) {
+	var vocabularies []vocab.Vocabulary
+	for _, id := range s.vocabIDs {
+		if len(vocabularies) < limit {
+			vocabularies = append(vocabularies, vocab.Vocabulary{ID: id, UserID: userID})
+		}
+	}
+	if len(vocabularies) == 0 {
+		return nil, vocab.ErrNoVocabsAvailable
+	}
+	return vocabularies, nil
+}
+
+func (s *fakeVocabService) IssueTestQuestion(ctx context.Context, userID, id string, mode vocab.QuizMode, direction vocab.Direction) (*vocab.TestVocabulary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.trashed[id] {
+		return nil, vocab.ErrVocabNotFound
+	}
+	s.nextID++
+	token := fmt.Sprintf("question-%d", s.nextID)
+	s.questions[token] = &fakeQuestion{vocabID: id}
+	return &vocab.TestVocabulary{ID: id, UserID: userID, Mode: mode, QuestionToken: token}, nil
+}
+
+// question finds an issued question, like the vocab service does for open and answered questions
+func (s *fakeVocabService) question(token string) (*fakeQuestion, error) {
+	question, ok := s.questions[token]
+	switch {
+	case !ok:
+		return nil, vocab.ErrQuestionNotFound
+	case s.trashed[question.vocabID]:
+		return nil, vocab.ErrVocabNotFound
+	}
+	return question, nil
+}
+
+func (s *fakeVocabService) GetTestQuestion(ctx context.Context, userID, token string) (*vocab.TestVocabulary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	question, err := s.question(token)
+	switch {
+	case err != nil:
+		return nil, err
+	case question.result != nil:
+		return nil, vocab.ErrQuestionAnswered
+	case question.expired:
+		return nil, vocab.ErrQuestionExpired
+	}
+	return &vocab.TestVocabulary{ID: question.vocabID, UserID: userID, QuestionToken: token}, nil
+}
+
+func (s *fakeVocabService) ValidateTestAnswer(ctx context.Context, userID, id string, req *vocab.TestResultRequest) (*vocab.TestResultResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	question, err := s.question(req.QuestionToken)
+	switch {
+	case err != nil:
+		return nil, err
+	case question.result != nil:
+		return nil, vocab.ErrQuestionAnswered
+	case question.expired:
+		return nil, vocab.ErrQuestionExpired
+	}
+
+	question.result = &vocab.TestResultResponse{Input: req.Input, Passed: req.Input == id}
+	s.graded[id]++
+	copied := *question.result
+	return &copied, nil
+}
+
+func (s *fakeVocabService) GetTestAnswer(ctx context.Context, userID, token string) (*vocab.TestResultResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	question, err := s.question(token)
+	if err != nil {
+		return nil, err
+	}
+	if question.result == nil {
+		return nil, vocab.ErrQuestionNotFound
+	}
+	copied := *question.result
+	return &copied, nil
+}
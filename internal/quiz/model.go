@@ -0,0 +1,118 @@
+package quiz
+
+import (
+	"time"
+
+	"vocabulary-app-be/internal/vocab"
+)
+
+// SessionStatus represents the state of a quiz session
+type SessionStatus string
+
+const (
+	SessionActive    SessionStatus = "active"
+	SessionCompleted SessionStatus = "completed"
+	SessionAbandoned SessionStatus = "abandoned"
+)
+
+// Session represents a server-side quiz session with a fixed question set
+type Session struct {
+	ID            string          `json:"id"`
+	UserID        string          `json:"user_id"`
+	Status        SessionStatus   `json:"status"`
+	FilterStatus  string          `json:"filter_status"`
+	DueOnly       bool            `json:"due_only"`
+	Direction     vocab.Direction `json:"direction"`
+	Mode          vocab.QuizMode  `json:"mode"`
+	QuestionCount int             `json:"question_count"`
+	AnsweredCount int             `json:"answered_count"`
+	CorrectCount  int             `json:"correct_count"`
+	StartedAt     time.Time       `json:"started_at"`
+	CompletedAt   *time.Time      `json:"completed_at,omitempty"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// SessionItem represents a single question of a quiz session
+type SessionItem struct {
+	ID           string     `json:"id"`
+	SessionID    string     `json:"session_id"`
+	VocabularyID string     `json:"vocabulary_id"`
+	Position     int        `json:"position"`
+	QuestionID   *string    `json:"question_id,omitempty"`
+	Input        *string    `json:"input,omitempty"`
+	Passed       *bool      `json:"passed,omitempty"`
+	AnsweredAt   *time.Time `json:"answered_at,omitempty"`
+}
+
+// CreateSessionRequest represents the create quiz session request payload
+type CreateSessionRequest struct {
+	Size      int             `json:"size" binding:"omitempty,min=1,max=100"`
+	Status    string          `json:"status"`
+	DueOnly   bool            `json:"due_only"`
+	Direction vocab.Direction `json:"direction"`
+	Mode      vocab.QuizMode  `json:"mode"`
+	DeckID    string          `json:"deck_id"`
+	Tag       string          `json:"tag"`
+}
+
+// AnswerRequest represents an answer to the current question of a session
+type AnswerRequest struct {
+	Input          string `json:"input" binding:"required,max=500"`
+	ResponseTimeMs *int64 `json:"response_time_ms" binding:"omitempty,min=0"`
+}
+
+// Question represents the current question of a session (without answers)
+type Question struct {
+	Position   int                  `json:"position"`
+	Vocabulary vocab.TestVocabulary `json:"vocabulary"`
+}
+
+// SessionSummary represents the result of a finished session
+type SessionSummary struct {
+	Total           int        `json:"total"`
+	Answered        int        `json:"answered"`
+	Correct         int        `json:"correct"`
+	Incorrect       int        `json:"incorrect"`
+	Score           float64    `json:"score"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	StartedAt       time.Time  `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// Summary builds the session summary
+func (s *Session) Summary() *SessionSummary {
+	summary := &SessionSummary{
+		Total:       s.QuestionCount,
+		Answered:    s.AnsweredCount,
+		Correct:     s.CorrectCount,
+		Incorrect:   s.AnsweredCount - s.CorrectCount,
+		StartedAt:   s.StartedAt,
+		CompletedAt: s.CompletedAt,
+	}
+
+	if s.QuestionCount > 0 {
+		summary.Score = float64(s.CorrectCount) * 100 / float64(s.QuestionCount)
+	}
+
+	end := s.UpdatedAt
+	if s.CompletedAt != nil {
+		end = *s.CompletedAt
+	}
+	summary.DurationSeconds = int64(end.Sub(s.StartedAt).Seconds())
+
+	return summary
+}
+
+// SessionResponse represents a session together with its current question or summary
+type SessionResponse struct {
+	Session  Session         `json:"session"`
+	Question *Question       `json:"question,omitempty"`
+	Summary  *SessionSummary `json:"summary,omitempty"`
+}
+
+// AnswerResponse represents the result of answering a session question
+type AnswerResponse struct {
+	Result  vocab.TestResultResponse `json:"result"`
+	Session Session                  `json:"session"`
+	Summary *SessionSummary          `json:"summary,omitempty"`
+}
@@ -0,0 +1,233 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Repository handles data access for quiz sessions
+type Repository interface {
+	Create(ctx context.Context, session *Session, vocabIDs []string) error
+	FindByID(ctx context.Context, id string) (*Session, error)
+	FindNextItem(ctx context.Context, sessionID string) (*SessionItem, error)
+	SetItemQuestion(ctx context.Context, item *SessionItem, questionID string) error
+	SkipItem(ctx context.Context, session *Session, item *SessionItem) error
+	RecordAnswer(ctx context.Context, session *Session, item *SessionItem, input string, passed bool) error
+	UpdateStatus(ctx context.Context, session *Session, status SessionStatus) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new quiz repository
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+const sessionColumns = `id, user_id, status, filter_status, due_only, direction, mode, question_count, answered_count, correct_count, started_at, completed_at, updated_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSession scans a row selected with sessionColumns into a Session
+func scanSession(row rowScanner, session *Session) error {
+	var completedAt sql.NullTime
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Status,
+		&session.FilterStatus,
+		&session.DueOnly,
+		&session.Direction,
+		&session.Mode,
+		&session.QuestionCount,
+		&session.AnsweredCount,
+		&session.CorrectCount,
+		&session.StartedAt,
+		&completedAt,
+		&session.UpdatedAt,
+	); err != nil {
+		return err
+	}
+	if completedAt.Valid {
+		session.CompletedAt = &completedAt.Time
+	}
+	return nil
+}
+
+// Create creates a session and its items in a single transaction
+func (r *repository) Create(ctx context.Context, session *Session, vocabIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO test_sessions (user_id, status, filter_status, due_only, direction, mode, question_count, started_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW()) RETURNING ` + sessionColumns
+
+	err = scanSession(tx.QueryRowContext(ctx, query,
+		session.UserID,
+		session.Status,
+		session.FilterStatus,
+		session.DueOnly,
+		session.Direction,
+		session.Mode,
+		len(vocabIDs),
+	), session)
+	if err != nil {
+		return err
+	}
+
+	itemQuery := `INSERT INTO test_session_items (session_id, vocabulary_id, position) VALUES ($1, $2, $3)`
+	for i, vocabID := range vocabIDs {
+		if _, err := tx.ExecContext(ctx, itemQuery, session.ID, vocabID, i+1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByID finds a session by ID
+func (r *repository) FindByID(ctx context.Context, id string) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM test_sessions WHERE id = $1`
+
+	var session Session
+	if err := scanSession(r.db.QueryRowContext(ctx, query, id), &session); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindNextItem finds the first unanswered item of a session
+func (r *repository) FindNextItem(ctx context.Context, sessionID string) (*SessionItem, error) {
+	query := `SELECT id, session_id, vocabulary_id, position, question_id
+			  FROM test_session_items WHERE session_id = $1 AND answered_at IS NULL
+			  ORDER BY position ASC LIMIT 1`
+
+	var item SessionItem
+	var questionID sql.NullString
+	err := r.db.QueryRowContext(ctx, query, sessionID).Scan(
+		&item.ID,
+		&item.SessionID,
+		&item.VocabularyID,
+		&item.Position,
+		&questionID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if questionID.Valid {
+		item.QuestionID = &questionID.String
+	}
+
+	return &item, nil
+}
+
+// SetItemQuestion links an item to the question issued for it
+func (r *repository) SetItemQuestion(ctx context.Context, item *SessionItem, questionID string) error {
+	query := `UPDATE test_session_items SET question_id = $1 WHERE id = $2`
+	if _, err := r.db.ExecContext(ctx, query, questionID, item.ID); err != nil {
+		return err
+	}
+
+	item.QuestionID = &questionID
+	return nil
+}
+
+// SkipItem removes an item whose vocabulary can no longer be tested from its session
+// and no longer counts it as a question. The session is completed once no unanswered items remain.
+func (r *repository) SkipItem(ctx context.Context, session *Session, item *SessionItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM test_session_items WHERE id = $1`, item.ID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		// Already skipped by a concurrent request
+		return nil
+	}
+
+	sessionQuery := `UPDATE test_sessions
+					 SET question_count = question_count - 1,
+					     status = CASE WHEN EXISTS (SELECT 1 FROM test_session_items WHERE session_id = $1 AND answered_at IS NULL) THEN status ELSE $2 END,
+					     completed_at = CASE WHEN EXISTS (SELECT 1 FROM test_session_items WHERE session_id = $1 AND answered_at IS NULL) THEN completed_at ELSE NOW() END,
+					     updated_at = NOW()
+					 WHERE id = $1 RETURNING ` + sessionColumns
+	if err := scanSession(tx.QueryRowContext(ctx, sessionQuery, session.ID, SessionCompleted), session); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RecordAnswer stores the graded answer of an item and updates the session counters.
+// An item is recorded only once: ErrQuestionAnswered is returned if it was already answered,
+// so recording the same answer again is safe. The session is completed once no unanswered items remain.
+func (r *repository) RecordAnswer(ctx context.Context, session *Session, item *SessionItem, input string, passed bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	itemQuery := `UPDATE test_session_items SET input = $1, passed = $2, answered_at = NOW()
+				  WHERE id = $3 AND answered_at IS NULL RETURNING answered_at`
+	var answeredAt time.Time
+	if err := tx.QueryRowContext(ctx, itemQuery, input, passed, item.ID).Scan(&answeredAt); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrQuestionAnswered
+		}
+		return err
+	}
+
+	correct := 0
+	if passed {
+		correct = 1
+	}
+
+	sessionQuery := `UPDATE test_sessions
+					 SET answered_count = answered_count + 1,
+					     correct_count = correct_count + $1,
+					     status = CASE WHEN EXISTS (SELECT 1 FROM test_session_items WHERE session_id = $2 AND answered_at IS NULL) THEN status ELSE $3 END,
+					     completed_at = CASE WHEN EXISTS (SELECT 1 FROM test_session_items WHERE session_id = $2 AND answered_at IS NULL) THEN completed_at ELSE NOW() END,
+					     updated_at = NOW()
+					 WHERE id = $2 RETURNING ` + sessionColumns
+	if err := scanSession(tx.QueryRowContext(ctx, sessionQuery, correct, session.ID, SessionCompleted), session); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	item.Input, item.Passed, item.AnsweredAt = &input, &passed, &answeredAt
+	return nil
+}
+
+// UpdateStatus changes the status of a session
+func (r *repository) UpdateStatus(ctx context.Context, session *Session, status SessionStatus) error {
+	query := `UPDATE test_sessions
+			  SET status = $1, completed_at = NOW(), updated_at = NOW()
+			  WHERE id = $2 RETURNING ` + sessionColumns
+
+	return scanSession(r.db.QueryRowContext(ctx, query, status, session.ID), session)
+}
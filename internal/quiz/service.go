@@ -0,0 +1,335 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+
+	"vocabulary-app-be/internal/vocab"
+)
+
+var (
+	ErrSessionNotFound  = errors.New("quiz session not found")
+	ErrUnauthorized     = errors.New("unauthorized access")
+	ErrSessionNotActive = errors.New("quiz session is not active")
+	ErrQuestionAnswered = errors.New("question has already been answered")
+	ErrInvalidStatus    = errors.New("invalid status")
+	ErrInvalidDirection = errors.New("invalid test direction")
+	ErrInvalidMode      = errors.New("invalid quiz mode")
+)
+
+// DefaultSessionSize is the number of questions in a session when no size is given
+const DefaultSessionSize = 10
+
+// Service handles business logic for quiz sessions
+type Service interface {
+	Create(ctx context.Context, userID string, req *CreateSessionRequest) (*SessionResponse, error)
+	GetByID(ctx context.Context, userID, id string) (*SessionResponse, error)
+	Next(ctx context.Context, userID, id string) (*SessionResponse, error)
+	Answer(ctx context.Context, userID, id string, req *AnswerRequest) (*AnswerResponse, error)
+	Abandon(ctx context.Context, userID, id string) (*SessionResponse, error)
+}
+
+type service struct {
+	repo         Repository
+	vocabService vocab.Service
+}
+
+// NewService creates a new quiz service
+func NewService(repo Repository, vocabService vocab.Service) Service {
+	return &service{repo: repo, vocabService: vocabService}
+}
+
+// Create creates a session with a fixed set of questions
+func (s *service) Create(ctx context.Context, userID string, req *CreateSessionRequest) (*SessionResponse, error) {
+	status := req.Status
+	if status == "" {
+		status = "all"
+	}
+	if status != "all" && !vocab.Status(status).IsValid() {
+		return nil, ErrInvalidStatus
+	}
+
+	direction := req.Direction
+	if direction == "" {
+		direction = vocab.DirectionForward
+	}
+	if !direction.IsValid() {
+		return nil, ErrInvalidDirection
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = vocab.ModeTyping
+	}
+	// Multiple-choice answers are graded by option, which sessions do not issue
+	if !mode.IsValid() || mode == vocab.ModeMultipleChoice {
+		return nil, ErrInvalidMode
+	}
+
+	size := req.Size
+	if size < 1 {
+		size = DefaultSessionSize
+	}
+
+	filter := vocab.TestFilter{
+		Status:    status,
+		DueOnly:   req.DueOnly,
+		Direction: direction,
+		Mode:      mode,
+		DeckID:    req.DeckID,
+		Tag:       req.Tag,
+	}
+	vocabularies, err := s.vocabService.GetForTest(ctx, userID, filter, size)
+	if err != nil {
+		return nil, err
+	}
+
+	vocabIDs := make([]string, 0, len(vocabularies))
+	for _, v := range vocabularies {
+		vocabIDs = append(vocabIDs, v.ID)
+	}
+
+	session := &Session{
+		UserID:       userID,
+		Status:       SessionActive,
+		FilterStatus: status,
+		DueOnly:      req.DueOnly,
+		Direction:    direction,
+		Mode:         mode,
+	}
+	if err := s.repo.Create(ctx, session, vocabIDs); err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, userID, session)
+}
+
+// GetByID retrieves a session with its current question or summary
+func (s *service) GetByID(ctx context.Context, userID, id string) (*SessionResponse, error) {
+	session, err := s.findSession(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, userID, session)
+}
+
+// Next retrieves the next unanswered question of an active session
+func (s *service) Next(ctx context.Context, userID, id string) (*SessionResponse, error) {
+	session, err := s.findSession(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == SessionAbandoned {
+		return nil, ErrSessionNotActive
+	}
+
+	return s.buildResponse(ctx, userID, session)
+}
+
+// Answer grades the answer to the current question and advances the session
+func (s *service) Answer(ctx context.Context, userID, id string, req *AnswerRequest) (*AnswerResponse, error) {
+	session, err := s.findSession(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != SessionActive {
+		return nil, ErrSessionNotActive
+	}
+
+	item, err := s.repo.FindNextItem(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrSessionNotActive
+	}
+	if item.QuestionID == nil {
+		// The question has not been shown yet
+		return nil, vocab.ErrQuestionNotFound
+	}
+
+	// The question can be graded only once, so a repeated or concurrent submit is not counted twice
+	result, err := s.vocabService.ValidateTestAnswer(ctx, userID, item.VocabularyID, &vocab.TestResultRequest{
+		QuestionToken:  *item.QuestionID,
+		Input:          req.Input,
+		ResponseTimeMs: req.ResponseTimeMs,
+	})
+	if errors.Is(err, vocab.ErrQuestionAnswered) {
+		// Graded before, but recording the answer in the session failed or is still in progress
+		result, err = s.vocabService.GetTestAnswer(ctx, userID, *item.QuestionID)
+	}
+	if err != nil {
+		if errors.Is(err, vocab.ErrVocabNotFound) {
+			// The vocabulary was moved to the trash after the session was created
+			if skipErr := s.repo.SkipItem(ctx, session, item); skipErr != nil {
+				return nil, skipErr
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.repo.RecordAnswer(ctx, session, item, result.Input, result.Passed); err != nil {
+		return nil, err
+	}
+
+	response := &AnswerResponse{
+		Result:  *result,
+		Session: *session,
+	}
+	if session.Status == SessionCompleted {
+		response.Summary = session.Summary()
+	}
+
+	return response, nil
+}
+
+// Abandon stops an active session before all questions are answered
+func (s *service) Abandon(ctx context.Context, userID, id string) (*SessionResponse, error) {
+	session, err := s.findSession(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != SessionActive {
+		return nil, ErrSessionNotActive
+	}
+
+	if err := s.repo.UpdateStatus(ctx, session, SessionAbandoned); err != nil {
+		return nil, err
+	}
+
+	return &SessionResponse{
+		Session: *session,
+		Summary: session.Summary(),
+	}, nil
+}
+
+// findSession finds a session and checks ownership
+func (s *service) findSession(ctx context.Context, userID, id string) (*Session, error) {
+	session, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	// Check ownership
+	if session.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	return session, nil
+}
+
+// buildResponse attaches the next question of an active session, or the summary of a finished one
+func (s *service) buildResponse(ctx context.Context, userID string, session *Session) (*SessionResponse, error) {
+	response := &SessionResponse{Session: *session}
+	if session.Status != SessionActive {
+		response.Summary = session.Summary()
+		return response, nil
+	}
+
+	var item *SessionItem
+	var question *vocab.TestVocabulary
+	for {
+		var err error
+		item, err = s.repo.FindNextItem(ctx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			// All remaining questions were removed (e.g. their vocabularies were deleted)
+			if err := s.repo.UpdateStatus(ctx, session, SessionCompleted); err != nil {
+				return nil, err
+			}
+			response.Session = *session
+			response.Summary = session.Summary()
+			return response, nil
+		}
+
+		question, err = s.currentQuestion(ctx, userID, session, item)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, vocab.ErrQuestionAnswered) {
+			// The answer was graded but not recorded in the session; record it instead of asking again
+			if err := s.recoverAnswer(ctx, userID, session, item); err != nil {
+				return nil, err
+			}
+			return s.buildResponse(ctx, userID, session)
+		}
+		if !errors.Is(err, vocab.ErrVocabNotFound) && !errors.Is(err, vocab.ErrModeUnavailable) {
+			return nil, err
+		}
+
+		// Skip questions whose vocabulary was moved to the trash or lost what the mode asks about
+		if err := s.repo.SkipItem(ctx, session, item); err != nil {
+			return nil, err
+		}
+		response.Session = *session
+		if session.Status != SessionActive {
+			// The skipped question was the last one left
+			response.Summary = session.Summary()
+			return response, nil
+		}
+	}
+
+	response.Question = &Question{
+		Position:   item.Position,
+		Vocabulary: *question,
+	}
+
+	return response, nil
+}
+
+// currentQuestion returns the question issued for an item. A new question is issued
+// if there is none yet or the previous one expired. ErrQuestionAnswered is returned
+// if the question was answered but its answer is not recorded in the session.
+func (s *service) currentQuestion(ctx context.Context, userID string, session *Session, item *SessionItem) (*vocab.TestVocabulary, error) {
+	if item.QuestionID != nil {
+		question, err := s.vocabService.GetTestQuestion(ctx, userID, *item.QuestionID)
+		switch err {
+		case nil:
+			return question, nil
+		case vocab.ErrQuestionNotFound, vocab.ErrQuestionExpired:
+		default:
+			return nil, err
+		}
+	}
+
+	question, err := s.vocabService.IssueTestQuestion(ctx, userID, item.VocabularyID, session.Mode, session.Direction)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetItemQuestion(ctx, item, question.QuestionToken); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// recoverAnswer records the graded answer to the question of an item in the session.
+// An answer recorded meanwhile by a concurrent request is left as it is.
+func (s *service) recoverAnswer(ctx context.Context, userID string, session *Session, item *SessionItem) error {
+	result, err := s.vocabService.GetTestAnswer(ctx, userID, *item.QuestionID)
+	if errors.Is(err, vocab.ErrVocabNotFound) {
+		return s.repo.SkipItem(ctx, session, item)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.RecordAnswer(ctx, session, item, result.Input, result.Passed)
+	if err != ErrQuestionAnswered {
+		return err
+	}
+
+	// Reload the counters updated by the concurrent request
+	recorded, err := s.findSession(ctx, userID, session.ID)
+	if err != nil {
+		return err
+	}
+	*session = *recorded
+	return nil
+}
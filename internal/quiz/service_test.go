@@ -0,0 +1,273 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"vocabulary-app-be/internal/vocab"
+)
+
+const testUserID = "user-1"
+
+// newTestSession creates a service over fakes and a session asking about each vocabulary
+func newTestSession(t *testing.T, vocabIDs ...string) (Service, *fakeRepository, *fakeVocabService, string) {
+	t.Helper()
+	repo := newFakeRepository()
+	vocabs := newFakeVocabService(vocabIDs...)
+	s := NewService(repo, vocabs)
+
+	resp, err := s.Create(context.Background(), testUserID, &CreateSessionRequest{Size: len(vocabIDs)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return s, repo, vocabs, resp.Session.ID
+}
+
+// current returns the vocabulary asked about by the current question of a session
+func current(t *testing.T, s Service, id string) string {
+	t.Helper()
+	resp, err := s.Next(context.Background(), testUserID, id)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if resp.Question == nil {
+		t.Fatalf("Next() returned no question, session %+v", resp.Session)
+	}
+	return resp.Question.Vocabulary.ID
+}
+
+func TestAnswerCompletesSession(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, id := newTestSession(t, "cat", "dog")
+
+	first := current(t, s, id)
+	resp, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: first})
+	if err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+	if !resp.Result.Passed || resp.Summary != nil {
+		t.Errorf("Answer() = %+v, want a passed answer without summary", resp)
+	}
+
+	current(t, s, id)
+	resp, err = s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "wrong"})
+	if err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+	if resp.Session.Status != SessionCompleted || resp.Summary == nil {
+		t.Fatalf("Answer() of the last question = %+v, want a completed session with summary", resp)
+	}
+	if resp.Summary.Total != 2 || resp.Summary.Correct != 1 || resp.Summary.Incorrect != 1 || resp.Summary.Score != 50 {
+		t.Errorf("summary = %+v, want 1 of 2 correct", resp.Summary)
+	}
+
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); !errors.Is(err, ErrSessionNotActive) {
+		t.Errorf("Answer() after completion error = %v, want %v", err, ErrSessionNotActive)
+	}
+}
+
+func TestAnswerTwice(t *testing.T) {
+	ctx := context.Background()
+	s, repo, vocabs, id := newTestSession(t, "cat", "dog")
+	current(t, s, id)
+	item, _ := repo.FindNextItem(ctx, id)
+
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+
+	// A second submit that read the same item before the first was recorded
+	session, _ := repo.FindByID(ctx, id)
+	if err := repo.RecordAnswer(ctx, session, item, "cat", true); !errors.Is(err, ErrQuestionAnswered) {
+		t.Errorf("RecordAnswer() twice error = %v, want %v", err, ErrQuestionAnswered)
+	}
+	if vocabs.graded["cat"] != 1 {
+		t.Errorf("answer graded %d times, want 1", vocabs.graded["cat"])
+	}
+	if session, _ := repo.FindByID(ctx, id); session.AnsweredCount != 1 {
+		t.Errorf("answered count = %d, want 1", session.AnsweredCount)
+	}
+}
+
+func TestAnswerRetryAfterFailedRecord(t *testing.T) {
+	ctx := context.Background()
+	s, repo, vocabs, id := newTestSession(t, "cat", "dog")
+	current(t, s, id)
+
+	repo.recordErr = errors.New("connection reset")
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); err == nil {
+		t.Fatal("Answer() with a failing record succeeded")
+	}
+
+	// The retry gets the result graded the first time, whatever it sends
+	resp, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "wrong"})
+	if err != nil {
+		t.Fatalf("Answer() retry error = %v", err)
+	}
+	if !resp.Result.Passed || resp.Result.Input != "cat" {
+		t.Errorf("Answer() retry result = %+v, want the first passed answer", resp.Result)
+	}
+	if resp.Session.AnsweredCount != 1 || resp.Session.CorrectCount != 1 {
+		t.Errorf("session = %+v, want one correct answer", resp.Session)
+	}
+	if vocabs.graded["cat"] != 1 {
+		t.Errorf("answer graded %d times, want 1", vocabs.graded["cat"])
+	}
+	if next := current(t, s, id); next != "dog" {
+		t.Errorf("next question about %q, want dog", next)
+	}
+}
+
+func TestNextRecordsUnrecordedAnswer(t *testing.T) {
+	ctx := context.Background()
+	s, repo, vocabs, id := newTestSession(t, "cat", "dog")
+	current(t, s, id)
+
+	repo.recordErr = errors.New("connection reset")
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); err == nil {
+		t.Fatal("Answer() with a failing record succeeded")
+	}
+
+	resp, err := s.Next(ctx, testUserID, id)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if resp.Question == nil || resp.Question.Vocabulary.ID != "dog" {
+		t.Fatalf("Next() = %+v, want the question about dog", resp.Question)
+	}
+	if resp.Session.AnsweredCount != 1 || resp.Session.CorrectCount != 1 {
+		t.Errorf("session = %+v, want the graded answer recorded", resp.Session)
+	}
+	if vocabs.graded["cat"] != 1 {
+		t.Errorf("answer graded %d times, want 1", vocabs.graded["cat"])
+	}
+}
+
+func TestAnswerGradingErrorKeepsQuestionOpen(t *testing.T) {
+	ctx := context.Background()
+	s, repo, vocabs, id := newTestSession(t, "cat")
+	current(t, s, id)
+	item, _ := repo.FindNextItem(ctx, id)
+	vocabs.questions[*item.QuestionID].expired = true
+
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); !errors.Is(err, vocab.ErrQuestionExpired) {
+		t.Fatalf("Answer() to an expired question error = %v, want %v", err, vocab.ErrQuestionExpired)
+	}
+
+	// The item is still open and gets a new question
+	if next := current(t, s, id); next != "cat" {
+		t.Fatalf("next question about %q, want cat", next)
+	}
+	resp, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"})
+	if err != nil {
+		t.Fatalf("Answer() to the new question error = %v", err)
+	}
+	if resp.Session.Status != SessionCompleted || resp.Session.CorrectCount != 1 {
+		t.Errorf("session = %+v, want completed with one correct answer", resp.Session)
+	}
+}
+
+func TestAnswerSkipsTrashedVocabulary(t *testing.T) {
+	ctx := context.Background()
+	s, _, vocabs, id := newTestSession(t, "cat", "dog")
+	current(t, s, id)
+	vocabs.trashed["cat"] = true
+
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); !errors.Is(err, vocab.ErrVocabNotFound) {
+		t.Fatalf("Answer() about a trashed vocabulary error = %v, want %v", err, vocab.ErrVocabNotFound)
+	}
+
+	resp, err := s.Next(ctx, testUserID, id)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if resp.Question == nil || resp.Question.Vocabulary.ID != "dog" || resp.Session.QuestionCount != 1 {
+		t.Errorf("Next() = %+v, want the question about dog in a session of 1", resp)
+	}
+}
+
+func TestAnswerSkippingLastQuestionCompletesSession(t *testing.T) {
+	ctx := context.Background()
+	s, repo, vocabs, id := newTestSession(t, "cat", "dog")
+	current(t, s, id)
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+	current(t, s, id)
+	vocabs.trashed["dog"] = true
+
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "dog"}); !errors.Is(err, vocab.ErrVocabNotFound) {
+		t.Fatalf("Answer() about a trashed vocabulary error = %v, want %v", err, vocab.ErrVocabNotFound)
+	}
+
+	session, _ := repo.FindByID(ctx, id)
+	if session.Status != SessionCompleted || session.CompletedAt == nil || session.QuestionCount != 1 {
+		t.Errorf("session = %+v, want it completed with 1 question", session)
+	}
+}
+
+func TestNextSkipsTrashedVocabularies(t *testing.T) {
+	ctx := context.Background()
+	s, _, vocabs, id := newTestSession(t, "cat", "dog")
+	current(t, s, id)
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+	vocabs.trashed["dog"] = true
+
+	resp, err := s.Next(ctx, testUserID, id)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if resp.Session.Status != SessionCompleted || resp.Summary == nil {
+		t.Fatalf("Next() = %+v, want the session completed", resp)
+	}
+	if resp.Summary.Total != 1 || resp.Summary.Score != 100 {
+		t.Errorf("summary = %+v, want 1 of 1 correct", resp.Summary)
+	}
+}
+
+func TestSessionOwnership(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, id := newTestSession(t, "cat")
+
+	if _, err := s.Next(ctx, "user-2", id); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Next() by another user error = %v, want %v", err, ErrUnauthorized)
+	}
+	if _, err := s.Answer(ctx, "user-2", id, &AnswerRequest{Input: "cat"}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Answer() by another user error = %v, want %v", err, ErrUnauthorized)
+	}
+	if _, err := s.Next(ctx, testUserID, "missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Next() of a missing session error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
+
+func TestAbandon(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, id := newTestSession(t, "cat", "dog")
+	current(t, s, id)
+
+	resp, err := s.Abandon(ctx, testUserID, id)
+	if err != nil {
+		t.Fatalf("Abandon() error = %v", err)
+	}
+	if resp.Session.Status != SessionAbandoned || resp.Summary == nil {
+		t.Errorf("Abandon() = %+v, want an abandoned session with summary", resp)
+	}
+	if _, err := s.Answer(ctx, testUserID, id, &AnswerRequest{Input: "cat"}); !errors.Is(err, ErrSessionNotActive) {
+		t.Errorf("Answer() after abandoning error = %v, want %v", err, ErrSessionNotActive)
+	}
+	if _, err := s.Next(ctx, testUserID, id); !errors.Is(err, ErrSessionNotActive) {
+		t.Errorf("Next() after abandoning error = %v, want %v", err, ErrSessionNotActive)
+	}
+}
+
+func TestCreateRejectsMultipleChoice(t *testing.T) {
+	s := NewService(newFakeRepository(), newFakeVocabService("cat"))
+
+	_, err := s.Create(context.Background(), testUserID, &CreateSessionRequest{Mode: vocab.ModeMultipleChoice})
+	if !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("Create() in multiple-choice mode error = %v, want %v", err, ErrInvalidMode)
+	}
+}
@@ -0,0 +1,95 @@
+// Package testutil provides the in-memory storage shared by the fake repositories of service tests
+package testutil
+
+import "fmt"
+
+// Sequence generates the IDs of rows stored by a fake repository
+type Sequence struct {
+	next int
+}
+
+// Next returns a new ID
+func (s *Sequence) Next() string {
+	s.next++
+	return fmt.Sprintf("id-%d", s.next)
+}
+
+// Table is an in-memory table of rows keyed by ID. Reads return copies, so callers cannot change
+// stored rows by accident, like rows scanned from the database. It is not safe for concurrent use;
+// fake repositories guard their tables with their own mutex.
+type Table[T any] struct {
+	rows map[string]*T
+	// ids lists the stored IDs in insertion order, so reads are deterministic
+	ids []string
+}
+
+// NewTable creates an empty table
+func NewTable[T any]() *Table[T] {
+	return &Table[T]{rows: map[string]*T{}}
+}
+
+// Insert stores a copy of row under id, replacing any row with that ID
+func (t *Table[T]) Insert(id string, row T) {
+	if _, ok := t.rows[id]; !ok {
+		t.ids = append(t.ids, id)
+	}
+	t.rows[id] = &row
+}
+
+// Get returns a copy of the row with the ID, or nil if there is none
+func (t *Table[T]) Get(id string) *T {
+	row, ok := t.rows[id]
+	if !ok {
+		return nil
+	}
+	copied := *row
+	return &copied
+}
+
+// Row returns the stored row with the ID for changing it in place, or nil if there is none
+func (t *Table[T]) Row(id string) *T {
+	return t.rows[id]
+}
+
+// Find returns a copy of the first row matching match, or nil if there is none
+func (t *Table[T]) Find(match func(row *T) bool) *T {
+	for _, id := range t.ids {
+		if row := t.rows[id]; match(row) {
+			copied := *row
+			return &copied
+		}
+	}
+	return nil
+}
+
+// Filter returns copies of the rows matching match in insertion order
+func (t *Table[T]) Filter(match func(row *T) bool) []T {
+	var rows []T
+	for _, id := range t.ids {
+		if row := t.rows[id]; match(row) {
+			rows = append(rows, *row)
+		}
+	}
+	return rows
+}
+
+// Each calls fn with every stored row in insertion order, for changing rows in place
+func (t *Table[T]) Each(fn func(row *T)) {
+	for _, id := range t.ids {
+		fn(t.rows[id])
+	}
+}
+
+// Delete deletes the row with the ID, if any
+func (t *Table[T]) Delete(id string) {
+	if _, ok := t.rows[id]; !ok {
+		return
+	}
+	delete(t.rows, id)
+	for i, existing := range t.ids {
+		if existing == id {
+			t.ids = append(t.ids[:i], t.ids[i+1:]...)
+			break
+		}
+	}
+}
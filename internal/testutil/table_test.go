@@ -0,0 +1,44 @@
+package testutil
+
+import "testing"
+
+type row struct {
+	name string
+}
+
+func TestTable(t *testing.T) {
+	var ids Sequence
+	table := NewTable[row]()
+	first, second := ids.Next(), ids.Next()
+	if first == second {
+		t.Fatalf("Sequence.Next() returned %q twice", first)
+	}
+	table.Insert(first, row{name: "cat"})
+	table.Insert(second, row{name: "dog"})
+
+	// Reads return copies
+	got := table.Get(first)
+	got.name = "changed"
+	if table.Get(first).name != "cat" {
+		t.Error("changing a row returned by Get() changed the stored row")
+	}
+	table.Row(first).name = "kitten"
+	if table.Get(first).name != "kitten" {
+		t.Error("changing a row returned by Row() did not change the stored row")
+	}
+
+	if found := table.Find(func(r *row) bool { return r.name == "dog" }); found == nil || found.name != "dog" {
+		t.Errorf("Find() = %v, want dog", found)
+	}
+	if rows := table.Filter(func(r *row) bool { return true }); len(rows) != 2 || rows[0].name != "kitten" {
+		t.Errorf("Filter() = %v, want both rows in insertion order", rows)
+	}
+
+	table.Delete(first)
+	if table.Get(first) != nil || table.Find(func(r *row) bool { return r.name == "kitten" }) != nil {
+		t.Error("deleted row is still found")
+	}
+	if table.Get("missing") != nil {
+		t.Error("Get() of a missing ID returned a row")
+	}
+}
@@ -0,0 +1,150 @@
+package vocab
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"html"
+	"strings"
+
+	"vocabulary-app-be/pkg/anki"
+)
+
+// MaxAnkiFileSize is the maximum size of an uploaded Anki package in bytes (media included)
+const MaxAnkiFileSize = 100 << 20
+
+// Anki note type written on export
+const (
+	ankiModelName       = "Vocabulary"
+	ankiDefaultDeckName = "Vocabulary"
+)
+
+// ankiFieldNames are the exported note fields, in the order filled by vocabulariesToAnki
+var ankiFieldNames = []string{"Word", "Translation", "Definition", "Example"}
+
+// Note field names accepted for each vocabulary field when no explicit mapping is given
+var ankiFieldAliases = map[string][]string{
+	"word":        {"Word", "Front", "Expression", "Term", "Vocabulary"},
+	"translation": {"Translation", "Back", "Meaning", "Answer"},
+	"definition":  {"Definition", "Description"},
+	"example":     {"Example", "Examples", "Sentence", "Example Sentence"},
+}
+
+// ankiNotesToRows maps Anki notes to import rows. Row numbers are note positions starting at 1.
+// Without a mapping, the first and second fields are used as word and translation
+// when no field has a known name.
+func ankiNotesToRows(notes []anki.Note, req *AnkiImportRequest) ([]ImportRow, []ImportRowError, error) {
+	if len(notes) == 0 {
+		return nil, nil, ErrEmptyImport
+	}
+	if len(notes) > MaxImportRows {
+		return nil, nil, ErrImportTooLarge
+	}
+
+	mapping := map[string]string{
+		"word":        req.WordField,
+		"definition":  req.DefinitionField,
+		"translation": req.TranslationField,
+		"example":     req.ExampleField,
+	}
+
+	rows := make([]ImportRow, 0, len(notes))
+	mapped := make(map[string]bool, len(mapping))
+	for i := range notes {
+		note := &notes[i]
+
+		values := make(map[string]string, len(mapping))
+		for field, name := range mapping {
+			if name != "" {
+				value, ok := note.Value(name)
+				mapped[field] = mapped[field] || ok
+				values[field] = value
+				continue
+			}
+			values[field], _ = note.Value(ankiFieldAliases[field]...)
+		}
+
+		// Fall back to the field order of basic note types
+		if _, ok := note.Value(ankiFieldAliases["word"]...); !ok && req.WordField == "" && len(note.Fields) > 0 {
+			values["word"] = note.Fields[0].Value
+			if _, ok := note.Value(ankiFieldAliases["translation"]...); !ok && req.TranslationField == "" && len(note.Fields) > 1 {
+				values["translation"] = note.Fields[1].Value
+			}
+		}
+
+		tags := Tags(note.Tags)
+		if note.Deck != "" && note.Deck != "Default" {
+			tags = append(Tags{note.Deck}, tags...)
+		}
+
+		rows = append(rows, ImportRow{
+			Row:         i + 1,
+			Word:        anki.StripHTML(strings.ReplaceAll(values["word"], "\n", " ")),
+			Definition:  anki.StripHTML(values["definition"]),
+			Translation: Translations(strings.Split(anki.StripHTML(values["translation"]), "\n")).Clean(),
+			Example:     Examples(splitValues(anki.StripHTML(values["example"]), "\n")),
+			Tags:        tags.Clean(),
+		})
+	}
+
+	// An explicitly mapped field must exist in at least one note type
+	for field, name := range mapping {
+		if name != "" && !mapped[field] {
+			return nil, nil, ErrInvalidColumnMapping
+		}
+	}
+
+	valid, rowErrors := validateImportRows(rows)
+	return valid, rowErrors, nil
+}
+
+// vocabulariesToAnki builds an Anki package holding one note per vocabulary
+func vocabulariesToAnki(deckName string, vocabularies []Vocabulary) *anki.Package {
+	if deckName == "" {
+		deckName = ankiDefaultDeckName
+	}
+
+	pkg := &anki.Package{
+		DeckName:   deckName,
+		ModelName:  ankiModelName,
+		FieldNames: ankiFieldNames,
+		Notes:      make([]anki.ExportNote, 0, len(vocabularies)),
+	}
+
+	for i := range vocabularies {
+		vocab := &vocabularies[i]
+		pkg.Notes = append(pkg.Notes, anki.ExportNote{
+			GUID: ankiGUID(vocab.ID),
+			Fields: []string{
+				html.EscapeString(vocab.Word),
+				joinHTMLLines(vocab.Translation),
+				html.EscapeString(vocab.Definition),
+				joinHTMLLines(vocab.Example),
+			},
+			Tags: vocab.Tags,
+			Card: anki.Card{
+				Due:          vocab.NextReviewAt,
+				IntervalDays: vocab.IntervalDays,
+				EaseFactor:   vocab.EaseFactor,
+				Repetitions:  vocab.Repetitions,
+				Lapses:       int(vocab.FailedTestCount),
+			},
+		})
+	}
+
+	return pkg
+}
+
+// ankiGUID derives a stable note GUID from a vocabulary ID so re-exports update the same notes
+func ankiGUID(id string) string {
+	sum := sha1.Sum([]byte("vocabulary:" + id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// joinHTMLLines escapes values and joins them with line breaks
+func joinHTMLLines(values []string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = html.EscapeString(value)
+	}
+	return strings.Join(escaped, "<br>")
+}
@@ -1,9 +1,11 @@
 package vocab
 
 import (
+	"bytes"
 	"net/http"
 	"strconv"
 
+	"vocabulary-app-be/pkg/anki"
 	"vocabulary-app-be/pkg/middleware"
 	"vocabulary-app-be/pkg/utils"
 
@@ -21,29 +23,52 @@ func NewController(service Service) *Controller {
 }
 
 // RegisterRoutes registers vocabulary routes
-func RegisterRoutes(router *gin.Engine, c *Controller, jwtSecret string) {
+func RegisterRoutes(router *gin.Engine, c *Controller, jwtSecret string, loginSessions middleware.SessionChecker) {
 	vocab := router.Group("/api/vocabularies")
 	// Add auth middleware
-	vocab.Use(middleware.AuthMiddleware(jwtSecret))
+	vocab.Use(middleware.AuthMiddleware(jwtSecret, loginSessions))
 	{
 		vocab.POST("", c.Create)
 		vocab.GET("", c.GetAll)
 		vocab.GET("/stats", c.GetStats)
+		vocab.POST("/bulk", c.Bulk)
+		vocab.POST("/import", c.Import)
+		vocab.POST("/import/anki", c.ImportAnki)
+		vocab.GET("/export", c.Export)
+		vocab.GET("/export/anki", c.ExportAnki)
+		vocab.GET("/trash", c.GetTrash)
 		vocab.GET("/:id", c.GetByID)
+		vocab.GET("/:id/history", c.GetHistory)
+		vocab.GET("/:id/revisions", c.GetRevisions)
+		vocab.POST("/:id/revisions/:revisionId/revert", c.RevertToRevision)
 		vocab.PUT("/:id", c.Update)
 		vocab.DELETE("/:id", c.Delete)
+		vocab.POST("/:id/restore", c.Restore)
 	}
 
 	// Test-specific routes
 	test := router.Group("/api/test")
-	test.Use(middleware.AuthMiddleware(jwtSecret))
+	test.Use(middleware.AuthMiddleware(jwtSecret, loginSessions))
 	{
 		test.GET("/vocabularies", c.GetRandomForTest)
-		test.GET("/vocabularies/:id/options", c.GetTestOptions)
+		test.GET("/due", c.GetDueForReview)
+		test.GET("/questions/:token", c.GetTestQuestion)
+		test.POST("/vocabularies/:id/question", c.IssueTestQuestion)
+		test.POST("/vocabularies/:id/options", c.IssueTestOptions)
 		test.POST("/vocabularies/:id/answer", c.SubmitTestAnswer)
+		test.POST("/vocabularies/:id/choice", c.SubmitTestChoice)
+		test.GET("/settings", c.GetQuizSettings)
+		test.PUT("/settings", c.UpdateQuizSettings)
 	}
 }
 
+// lexicalErrorMessages are the responses for invalid lexical metadata
+var lexicalErrorMessages = map[error]string{
+	ErrInvalidPartOfSpeech: "Invalid part_of_speech. Use: noun, verb, adjective, adverb, pronoun, preposition, conjunction, determiner, numeral, interjection, particle, or phrase",
+	ErrInvalidGender:       "Invalid gender. Use: masculine, feminine, neuter, or common",
+	ErrInvalidRegister:     "Invalid register. Use: neutral, formal, informal, colloquial, slang, literary, technical, archaic, or vulgar",
+}
+
 // getUserID extracts user ID from context (set by auth middleware)
 func getUserID(ctx *gin.Context) string {
 	userID, exists := ctx.Get("userID")
@@ -71,7 +96,12 @@ func (c *Controller) Create(ctx *gin.Context) {
 	vocab, err := c.service.Create(ctx.Request.Context(), userID, &req)
 	if err != nil {
 		ctx.Error(err)
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create vocabulary")
+		switch err {
+		case ErrInvalidPartOfSpeech, ErrInvalidGender, ErrInvalidRegister:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, lexicalErrorMessages[err])
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create vocabulary")
+		}
 		return
 	}
 
@@ -102,16 +132,269 @@ func (c *Controller) GetAll(ctx *gin.Context) {
 		status = ""
 	}
 
-	response, err := c.service.GetByUserID(ctx.Request.Context(), userID, page, pageSize, search, status)
+	filter := ListFilter{
+		Search: search,
+		Status: status,
+		DeckID: ctx.Query("deck"),
+		Tag:    ctx.Query("tag"),
+		Sort:   SortField(ctx.Query("sort")),
+		Order:  SortOrder(ctx.Query("order")),
+
+		PartOfSpeech: PartOfSpeech(ctx.Query("part_of_speech")),
+		Gender:       Gender(ctx.Query("gender")),
+		Register:     Register(ctx.Query("register")),
+	}
+
+	lexical := Lexical{PartOfSpeech: filter.PartOfSpeech, Gender: filter.Gender, Register: filter.Register}
+	if err := lexical.Validate(); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, lexicalErrorMessages[err])
+		return
+	}
+
+	// Passing a cursor, even an empty one, switches to cursor pagination.
+	// The total is only counted there when asked for with with_total=true.
+	var response *VocabListResponse
+	var err error
+	if cursor, ok := ctx.GetQuery("cursor"); ok {
+		withTotal, _ := strconv.ParseBool(ctx.Query("with_total"))
+		response, err = c.service.GetByUserIDAfter(ctx.Request.Context(), userID, cursor, pageSize, filter, withTotal)
+	} else {
+		response, err = c.service.GetByUserID(ctx.Request.Context(), userID, page, pageSize, filter)
+	}
 	if err != nil {
 		ctx.Error(err)
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get vocabularies")
+		switch err {
+		case ErrInvalidSort:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid sort. Use: word, created_at, updated_at, test_count, accuracy, next_review, or relevance (with search)")
+		case ErrInvalidSortOrder:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid order. Use: asc or desc")
+		case ErrInvalidCursor:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid cursor")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get vocabularies")
+		}
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Vocabularies retrieved successfully", response)
 }
 
+// Bulk handles applying an action to many vocabularies at once
+func (c *Controller) Bulk(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req BulkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := c.service.Bulk(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidBulkAction:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid action. Use: delete, set_status, add_tag, remove_tag, or reset_counters")
+		case ErrInvalidStatus:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid status. Use: learning or memorized")
+		case ErrInvalidTag:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "A tag is required for this action")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to apply bulk action")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Bulk action applied successfully", response)
+}
+
+// Import handles importing vocabularies from an uploaded CSV file
+func (c *Controller) Import(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxImportFileSize+1<<20)
+
+	var req ImportRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.File.Size > MaxImportFileSize {
+		utils.ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "Import file is too large")
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Failed to read import file")
+		return
+	}
+	defer file.Close()
+
+	result, err := c.service.Import(ctx.Request.Context(), userID, file, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidImport:
+			utils.ErrorResponseWithData(ctx, http.StatusUnprocessableEntity, "Import contains invalid rows", result)
+		case ErrInvalidDuplicateStrategy:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid on_duplicate. Use: skip, overwrite, or merge")
+		case ErrInvalidColumnMapping:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid column mapping. A word column is required")
+		case ErrRestoreNeedsOverwrite:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "restore_progress requires on_duplicate=overwrite")
+		case ErrInvalidImportOptions:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid import options")
+		case ErrUnsupportedImportFile:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Unsupported import file. Use a CSV file or a JSON export")
+		case ErrEmptyImport:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Import file contains no rows")
+		case ErrImportTooLarge:
+			utils.ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "Import file contains too many rows")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to import vocabularies")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Vocabularies imported successfully", result)
+}
+
+// ImportAnki handles importing vocabularies from an uploaded Anki package (.apkg)
+func (c *Controller) ImportAnki(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxAnkiFileSize+1<<20)
+
+	var req AnkiImportRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.File.Size > MaxAnkiFileSize {
+		utils.ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "Anki package is too large")
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Failed to read Anki package")
+		return
+	}
+	defer file.Close()
+
+	result, err := c.service.ImportAnki(ctx.Request.Context(), userID, file, req.File.Size, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidImport:
+			utils.ErrorResponseWithData(ctx, http.StatusUnprocessableEntity, "Import contains invalid notes", result)
+		case ErrInvalidDuplicateStrategy:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid on_duplicate. Use: skip, overwrite, or merge")
+		case ErrInvalidColumnMapping:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid field mapping. Mapped fields must exist in the package")
+		case ErrEmptyImport:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Anki package contains no notes")
+		case ErrImportTooLarge, anki.ErrCollectionTooBig:
+			utils.ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "Anki package contains too many notes")
+		case anki.ErrInvalidPackage:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid Anki package")
+		case anki.ErrUnsupportedFormat:
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "Unsupported Anki package. Export it with \"Support older Anki versions\" enabled")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to import Anki package")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Vocabularies imported successfully", result)
+}
+
+// Export handles streaming all vocabularies of a user as JSON or CSV
+func (c *Controller) Export(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	format := ExportFormat(ctx.DefaultQuery("format", string(ExportFormatJSON)))
+	if !format.IsValid() {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid format. Use: json or csv")
+		return
+	}
+
+	filter := ListFilter{
+		DeckID: ctx.Query("deck"),
+		Tag:    ctx.Query("tag"),
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == ExportFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", `attachment; filename="vocabulary.`+string(format)+`"`)
+	ctx.Status(http.StatusOK)
+
+	if err := c.service.Export(ctx.Request.Context(), userID, format, filter, ctx.Writer); err != nil {
+		ctx.Error(err)
+		// Errors can only be reported before streaming has started
+		if !ctx.Writer.Written() {
+			ctx.Writer.Header().Del("Content-Type")
+			ctx.Writer.Header().Del("Content-Disposition")
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to export vocabularies")
+		}
+	}
+}
+
+// ExportAnki handles exporting vocabularies as an Anki package (.apkg)
+func (c *Controller) ExportAnki(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter := ListFilter{
+		DeckID: ctx.Query("deck"),
+		Tag:    ctx.Query("tag"),
+	}
+
+	// The package is built completely before sending so errors can still be reported
+	var buf bytes.Buffer
+	if err := c.service.ExportAnki(ctx.Request.Context(), userID, filter, &buf); err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "No vocabularies to export")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to export Anki package")
+		}
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="vocabulary.apkg"`)
+	ctx.Data(http.StatusOK, "application/octet-stream", buf.Bytes())
+}
+
 // GetStats handles getting vocabulary statistics
 func (c *Controller) GetStats(ctx *gin.Context) {
 	userID := getUserID(ctx)
@@ -120,7 +403,12 @@ func (c *Controller) GetStats(ctx *gin.Context) {
 		return
 	}
 
-	stats, err := c.service.GetVocabStats(ctx.Request.Context(), userID)
+	filter := ListFilter{
+		DeckID: ctx.Query("deck"),
+		Tag:    ctx.Query("tag"),
+	}
+
+	stats, err := c.service.GetVocabStats(ctx.Request.Context(), userID, filter)
 	if err != nil {
 		ctx.Error(err)
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get vocabulary stats")
@@ -161,6 +449,110 @@ func (c *Controller) GetByID(ctx *gin.Context) {
 	utils.SuccessResponse(ctx, http.StatusOK, "Vocabulary retrieved successfully", vocab)
 }
 
+// GetHistory handles getting the review history of a vocabulary
+func (c *Controller) GetHistory(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	history, err := c.service.GetReviewHistory(ctx.Request.Context(), userID, id, page, pageSize)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get review history")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Review history retrieved successfully", history)
+}
+
+// GetRevisions handles getting the edit history of a vocabulary
+func (c *Controller) GetRevisions(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	revisions, err := c.service.GetRevisions(ctx.Request.Context(), userID, id, page, pageSize)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get revisions")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Revisions retrieved successfully", revisions)
+}
+
+// RevertToRevision handles restoring a vocabulary to an earlier revision
+func (c *Controller) RevertToRevision(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	revisionID := ctx.Param("revisionId")
+	if id == "" || revisionID == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	vocab, err := c.service.RevertToRevision(ctx.Request.Context(), userID, id, revisionID)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrRevisionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Revision not found")
+		case ErrWordExists:
+			utils.ErrorResponse(ctx, http.StatusConflict, "A vocabulary with this word already exists")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to revert vocabulary")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Vocabulary reverted successfully", vocab)
+}
+
 // Update handles vocabulary update
 func (c *Controller) Update(ctx *gin.Context) {
 	userID := getUserID(ctx)
@@ -190,6 +582,8 @@ func (c *Controller) Update(ctx *gin.Context) {
 			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
 		case ErrUnauthorized:
 			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrInvalidPartOfSpeech, ErrInvalidGender, ErrInvalidRegister:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, lexicalErrorMessages[err])
 		default:
 			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update vocabulary")
 		}
@@ -229,6 +623,60 @@ func (c *Controller) Delete(ctx *gin.Context) {
 	utils.SuccessResponse(ctx, http.StatusNoContent, "Vocabulary deleted successfully", nil)
 }
 
+// GetTrash handles listing the vocabularies in the trash
+func (c *Controller) GetTrash(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	response, err := c.service.GetTrash(ctx.Request.Context(), userID, page, pageSize)
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get trash")
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Trash retrieved successfully", response)
+}
+
+// Restore handles moving a vocabulary out of the trash
+func (c *Controller) Restore(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	vocab, err := c.service.Restore(ctx.Request.Context(), userID, id)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found in trash")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrWordExists:
+			utils.ErrorResponse(ctx, http.StatusConflict, "A vocabulary with this word already exists")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to restore vocabulary")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Vocabulary restored successfully", vocab)
+}
+
 // GetRandomForTest handles getting a random vocabulary for testing
 func (c *Controller) GetRandomForTest(ctx *gin.Context) {
 	userID := getUserID(ctx)
@@ -244,10 +692,29 @@ func (c *Controller) GetRandomForTest(ctx *gin.Context) {
 		return
 	}
 
-	vocab, err := c.service.GetRandomForTest(ctx.Request.Context(), userID, status)
+	filter := TestFilter{
+		Status:    status,
+		Direction: Direction(ctx.DefaultQuery("direction", string(DirectionForward))),
+		Mode:      QuizMode(ctx.DefaultQuery("mode", string(ModeTyping))),
+		DeckID:    ctx.Query("deck"),
+		Tag:       ctx.Query("tag"),
+
+		PartOfSpeech: PartOfSpeech(ctx.Query("part_of_speech")),
+	}
+
+	if !filter.PartOfSpeech.IsValid() {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, lexicalErrorMessages[ErrInvalidPartOfSpeech])
+		return
+	}
+
+	vocab, err := c.service.GetRandomForTest(ctx.Request.Context(), userID, filter)
 	if err != nil {
 		ctx.Error(err)
 		switch err {
+		case ErrInvalidDirection:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid direction. Use: forward or reverse")
+		case ErrInvalidQuizMode:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid mode. Use: typing, multiple_choice, definition, cloze, pronunciation, gender, part_of_speech, or form")
 		case ErrNoVocabsAvailable:
 			utils.ErrorResponse(ctx, http.StatusNotFound, "No vocabularies available for testing")
 		default:
@@ -259,8 +726,63 @@ func (c *Controller) GetRandomForTest(ctx *gin.Context) {
 	utils.SuccessResponse(ctx, http.StatusOK, "Vocabulary retrieved successfully", vocab)
 }
 
-// GetTestOptions handles getting multiple-choice options for a vocabulary
-func (c *Controller) GetTestOptions(ctx *gin.Context) {
+// GetDueForReview handles getting the queue of vocabularies due for review
+func (c *Controller) GetDueForReview(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid limit. Use a number between 1 and 100")
+		return
+	}
+
+	response, err := c.service.GetDueForReview(ctx.Request.Context(), userID, limit)
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get vocabularies due for review")
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Due vocabularies retrieved successfully", response)
+}
+
+// GetTestQuestion handles getting the prompt of an issued free-text question that is still open
+func (c *Controller) GetTestQuestion(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	question, err := c.service.GetTestQuestion(ctx.Request.Context(), userID, ctx.Param("token"))
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrQuestionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Test question not found")
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrQuestionAnswered:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Test question has already been answered")
+		case ErrQuestionExpired:
+			utils.ErrorResponse(ctx, http.StatusGone, "Test question has expired")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get test question")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Test question retrieved successfully", question)
+}
+
+// IssueTestQuestion handles issuing a free-text question about a vocabulary
+func (c *Controller) IssueTestQuestion(ctx *gin.Context) {
 	userID := getUserID(ctx)
 	if userID == "" {
 		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
@@ -273,14 +795,71 @@ func (c *Controller) GetTestOptions(ctx *gin.Context) {
 		return
 	}
 
-	options, err := c.service.GetTestOptions(ctx.Request.Context(), userID, vocabID)
+	mode := QuizMode(ctx.DefaultQuery("mode", string(ModeTyping)))
+	direction := Direction(ctx.DefaultQuery("direction", string(DirectionForward)))
+
+	question, err := c.service.IssueTestQuestion(ctx.Request.Context(), userID, vocabID, mode, direction)
 	if err != nil {
 		ctx.Error(err)
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get test options")
+		switch err {
+		case ErrInvalidDirection:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid direction. Use: forward or reverse")
+		case ErrInvalidQuizMode:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid mode. Use: typing, definition, cloze, pronunciation, gender, part_of_speech, or form")
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrModeUnavailable:
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "This vocabulary cannot be tested in this mode")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to issue test question")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "Test question issued successfully", question)
+}
+
+// IssueTestOptions handles issuing a multiple-choice question with options for a vocabulary
+func (c *Controller) IssueTestOptions(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	vocabID := ctx.Param("id")
+	if vocabID == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid vocabulary ID")
+		return
+	}
+
+	direction := Direction(ctx.DefaultQuery("direction", string(DirectionForward)))
+	// Built-in distractors are opt-in, as questions using them do not count toward test results
+	allowFallback := ctx.Query("fallback") == "true"
+
+	options, err := c.service.IssueTestOptions(ctx.Request.Context(), userID, vocabID, direction, allowFallback)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidDirection:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid direction. Use: forward or reverse")
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrNotEnoughOptions:
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "Not enough vocabularies to build a full option set")
+		case ErrModeUnavailable:
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "This vocabulary has no translation to choose")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to issue test options")
+		}
 		return
 	}
 
-	utils.SuccessResponse(ctx, http.StatusOK, "Test options retrieved successfully", options)
+	utils.SuccessResponse(ctx, http.StatusCreated, "Test options issued successfully", options)
 }
 
 // SubmitTestAnswer handles validating a test answer
@@ -304,14 +883,24 @@ func (c *Controller) SubmitTestAnswer(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.service.ValidateTestAnswer(ctx.Request.Context(), userID, id, req.Input)
+	result, err := c.service.ValidateTestAnswer(ctx.Request.Context(), userID, id, &req)
 	if err != nil {
 		ctx.Error(err)
 		switch err {
+		case ErrQuestionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Test question not found")
 		case ErrVocabNotFound:
 			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
 		case ErrUnauthorized:
 			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrQuestionAnswered:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Test question has already been answered")
+		case ErrQuestionExpired:
+			utils.ErrorResponse(ctx, http.StatusGone, "Test question has expired")
+		case ErrModeUnavailable:
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "This vocabulary has no metadata for this mode")
+		case ErrInvalidForm:
+			utils.ErrorResponse(ctx, http.StatusConflict, "The form asked for was removed from this vocabulary")
 		default:
 			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to validate test answer")
 		}
@@ -320,3 +909,97 @@ func (c *Controller) SubmitTestAnswer(ctx *gin.Context) {
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Test answer validated successfully", result)
 }
+
+// SubmitTestChoice handles grading a selected multiple-choice option
+func (c *Controller) SubmitTestChoice(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req TestChoiceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.service.ValidateTestChoice(ctx.Request.Context(), userID, id, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrQuestionNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Test question not found")
+		case ErrVocabNotFound:
+			utils.ErrorResponse(ctx, http.StatusNotFound, "Vocabulary not found")
+		case ErrUnauthorized:
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Access denied")
+		case ErrQuestionAnswered:
+			utils.ErrorResponse(ctx, http.StatusConflict, "Test question has already been answered")
+		case ErrQuestionExpired:
+			utils.ErrorResponse(ctx, http.StatusGone, "Test question has expired")
+		case ErrInvalidOption:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid option")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to validate test choice")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Test choice validated successfully", result)
+}
+
+// GetQuizSettings handles getting the quiz settings of the user
+func (c *Controller) GetQuizSettings(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	settings, err := c.service.GetQuizSettings(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get quiz settings")
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Quiz settings retrieved successfully", settings)
+}
+
+// UpdateQuizSettings handles updating the quiz settings of the user
+func (c *Controller) UpdateQuizSettings(ctx *gin.Context) {
+	userID := getUserID(ctx)
+	if userID == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdateQuizSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	settings, err := c.service.UpdateQuizSettings(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		ctx.Error(err)
+		switch err {
+		case ErrInvalidTolerance:
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid answer tolerance. Use: strict, normal, or lenient")
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update quiz settings")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Quiz settings updated successfully", settings)
+}
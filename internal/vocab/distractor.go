@@ -0,0 +1,150 @@
+package vocab
+
+import (
+	"math/rand/v2"
+	"sort"
+
+	"vocabulary-app-be/pkg/grading"
+)
+
+// Multiple-choice option set parameters
+const (
+	// OptionCount is the number of options in a full multiple-choice question
+	OptionCount = 4
+	// distractorPoolSize is how many candidate vocabularies are scored for distractors
+	distractorPoolSize = 50
+	// distractorJitter adds randomness so the same distractors are not always picked
+	distractorJitter = 0.15
+	// samePartOfSpeechBonus favors distractors of the answer's part of speech,
+	// so a verb is not offered among nouns
+	samePartOfSpeechBonus = 0.3
+	// sharedTagBonus favors distractors from the same deck as the answer,
+	// which usually belong to the same topic
+	sharedTagBonus = 0.2
+)
+
+// fallbackDistractors are built-in options used for practice questions when a collection is too small.
+// They are plain English words, so they work best for English translations.
+var fallbackDistractors = []string{
+	"house", "water", "light", "table", "friend", "window", "garden", "river",
+	"mountain", "letter", "number", "money", "morning", "evening", "people",
+	"question", "answer", "family", "school", "market", "travel", "happy",
+	"quickly", "strong", "little", "bright", "heavy", "simple", "to open",
+	"to carry", "to listen", "to build", "to forget", "to remember", "to follow",
+	"weather", "journey", "kitchen", "mirror", "bridge", "village", "forest",
+}
+
+// scoredDistractor is a candidate option with its similarity score
+type scoredDistractor struct {
+	vocab *Vocabulary
+	text  string
+	score float64
+}
+
+// selectDistractors picks up to count candidates that look most like the correct answer,
+// preferring candidates with the same part of speech or a shared tag.
+// Candidates whose option text matches the correct answer (or each other) are skipped.
+func selectDistractors(target *Vocabulary, candidates []Vocabulary, direction Direction, count int) []*Vocabulary {
+	correct := toTestOption(target, direction)
+	correctText := optionText(&correct)
+
+	// Texts that would make a second correct answer
+	taken := map[string]bool{grading.Normalize(correctText): true}
+	if direction != DirectionReverse {
+		for _, translation := range target.Translation {
+			taken[grading.Normalize(translation)] = true
+		}
+	}
+
+	scored := make([]scoredDistractor, 0, len(candidates))
+	for i := range candidates {
+		option := toTestOption(&candidates[i], direction)
+		text := optionText(&option)
+		if text == "" {
+			continue
+		}
+		score := distractorScore(correctText, text) + rand.Float64()*distractorJitter
+		if target.PartOfSpeech != "" && candidates[i].PartOfSpeech == target.PartOfSpeech {
+			score += samePartOfSpeechBonus
+		}
+		if target.Tags.Shares(candidates[i].Tags) {
+			score += sharedTagBonus
+		}
+		scored = append(scored, scoredDistractor{
+			vocab: &candidates[i],
+			text:  text,
+			score: score,
+		})
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	selected := make([]*Vocabulary, 0, count)
+	for _, candidate := range scored {
+		if len(selected) == count {
+			break
+		}
+		key := grading.Normalize(candidate.text)
+		if taken[key] {
+			continue
+		}
+		taken[key] = true
+		selected = append(selected, candidate.vocab)
+	}
+
+	return selected
+}
+
+// distractorScore rates how plausible a candidate is as a wrong answer, from 0 to about 1
+func distractorScore(correctText, text string) float64 {
+	a := grading.FoldDiacritics(grading.Normalize(correctText))
+	b := grading.FoldDiacritics(grading.Normalize(text))
+
+	la, lb := len([]rune(a)), len([]rune(b))
+	longest := max(la, lb, 1)
+
+	// Similar length
+	lengthScore := 1 - float64(abs(la-lb))/float64(longest)
+
+	// Similar spelling
+	spellingScore := 1 - float64(grading.Distance(a, b))/float64(longest)
+
+	return 0.4*lengthScore + 0.6*spellingScore
+}
+
+// fillFallbackDistractors adds built-in options until the set has count options
+func fillFallbackDistractors(options []TestOption, direction Direction, count int) []TestOption {
+	taken := make(map[string]bool, len(options))
+	for i := range options {
+		taken[grading.Normalize(optionText(&options[i]))] = true
+	}
+
+	for _, i := range rand.Perm(len(fallbackDistractors)) {
+		if len(options) >= count {
+			break
+		}
+		text := fallbackDistractors[i]
+		if taken[grading.Normalize(text)] {
+			continue
+		}
+		taken[grading.Normalize(text)] = true
+
+		option := TestOption{Translation: text}
+		if direction == DirectionReverse {
+			option = TestOption{Word: text}
+		}
+		options = append(options, option)
+	}
+
+	return options
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
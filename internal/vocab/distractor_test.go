@@ -0,0 +1,41 @@
+package vocab
+
+import (
+	"testing"
+
+	"vocabulary-app-be/pkg/grading"
+)
+
+func TestFillFallbackDistractors(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   []TestOption
+		direction Direction
+	}{
+		{name: "forward", options: []TestOption{{Translation: "house"}}, direction: DirectionForward},
+		{name: "reverse", options: []TestOption{{Word: "Haus"}, {Word: "water"}}, direction: DirectionReverse},
+		{name: "full set", options: []TestOption{{Translation: "a"}, {Translation: "b"}, {Translation: "c"}, {Translation: "d"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := fillFallbackDistractors(append([]TestOption(nil), tt.options...), tt.direction, OptionCount)
+			if len(options) != OptionCount {
+				t.Fatalf("got %d options, want %d", len(options), OptionCount)
+			}
+
+			seen := map[string]bool{}
+			for i := range options {
+				text := grading.Normalize(optionText(&options[i]))
+				if seen[text] {
+					t.Errorf("option %q appears twice", text)
+				}
+				seen[text] = true
+
+				if i >= len(tt.options) && tt.direction == DirectionReverse && options[i].Word == "" {
+					t.Errorf("reverse fallback option %d shows no word", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package vocab
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportSeparator joins multiple values in a CSV cell, matching the CSV import default
+const exportSeparator = "|"
+
+// exportCSVHeader is the header of CSV exports. The content columns use the CSV import names.
+var exportCSVHeader = []string{
+	"word", "definition", "translation", "example", "tags", "status",
+	"test_count", "passed_test_count", "failed_test_count",
+	"reverse_test_count", "reverse_passed_test_count", "reverse_failed_test_count",
+	"ease_factor", "interval_days", "repetitions", "next_review_at", "created_at", "updated_at",
+}
+
+// exportWriter writes vocabularies to an export file one at a time
+type exportWriter interface {
+	Write(vocab *Vocabulary) error
+	Close() error
+}
+
+// newExportWriter creates an export writer for the given format.
+// Nothing is written to w before the first vocabulary or Close.
+func newExportWriter(w io.Writer, format ExportFormat) exportWriter {
+	if format == ExportFormatCSV {
+		return &csvExportWriter{w: csv.NewWriter(w)}
+	}
+	return &jsonExportWriter{w: w}
+}
+
+// jsonExportWriter streams an ExportDocument
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+// Write implements exportWriter
+func (j *jsonExportWriter) Write(vocab *Vocabulary) error {
+	if j.count == 0 {
+		if err := j.writeHead(); err != nil {
+			return err
+		}
+	} else if _, err := io.WriteString(j.w, ","); err != nil {
+		return err
+	}
+	j.count++
+
+	data, err := json.Marshal(vocab.ToExport())
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+// Close implements exportWriter
+func (j *jsonExportWriter) Close() error {
+	if j.count == 0 {
+		if err := j.writeHead(); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(j.w, "]}\n")
+	return err
+}
+
+// writeHead writes the document fields before the vocabularies list
+func (j *jsonExportWriter) writeHead() error {
+	format, err := json.Marshal(ExportDocumentFormat)
+	if err != nil {
+		return err
+	}
+	exportedAt, err := json.Marshal(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	// Keep the field names in sync with the ExportDocument json tags
+	_, err = fmt.Fprintf(j.w, `{"format":%s,"version":%d,"exported_at":%s,"vocabularies":[`,
+		format, ExportDocumentVersion, exportedAt)
+	return err
+}
+
+// csvExportWriter streams a CSV file with a header row
+type csvExportWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// Write implements exportWriter
+func (c *csvExportWriter) Write(vocab *Vocabulary) error {
+	if !c.wroteHeader {
+		if err := c.w.Write(exportCSVHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+
+	record := []string{
+		vocab.Word,
+		vocab.Definition,
+		strings.Join(vocab.Translation, exportSeparator),
+		strings.Join(vocab.Example, exportSeparator),
+		strings.Join(vocab.Tags, exportSeparator),
+		string(vocab.Status),
+		strconv.FormatInt(vocab.TestCount, 10),
+		strconv.FormatInt(vocab.PassedTestCount, 10),
+		strconv.FormatInt(vocab.FailedTestCount, 10),
+		strconv.FormatInt(vocab.ReverseTestCount, 10),
+		strconv.FormatInt(vocab.ReversePassedTestCount, 10),
+		strconv.FormatInt(vocab.ReverseFailedTestCount, 10),
+		strconv.FormatFloat(vocab.EaseFactor, 'f', -1, 64),
+		strconv.Itoa(vocab.IntervalDays),
+		strconv.Itoa(vocab.Repetitions),
+		vocab.NextReviewAt.UTC().Format(time.RFC3339),
+		vocab.CreatedAt.UTC().Format(time.RFC3339),
+		vocab.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	return c.w.Write(record)
+}
+
+// Close implements exportWriter
+func (c *csvExportWriter) Close() error {
+	if !c.wroteHeader {
+		if err := c.w.Write(exportCSVHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,58 @@
+package vocab
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONExportWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		vocabs []*Vocabulary
+	}{
+		{"empty", nil},
+		{"one", []*Vocabulary{{Word: "house", Translation: Translations{"casa"}}}},
+		{"several", []*Vocabulary{
+			{Word: "house", Translation: Translations{"casa"}},
+			{Word: "cat", Translation: Translations{"gato"}, Tags: Tags{"animals"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := newExportWriter(&buf, ExportFormatJSON)
+			for _, vocab := range tt.vocabs {
+				if err := w.Write(vocab); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			var doc ExportDocument
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("output is not a valid document: %v\n%s", err, buf.String())
+			}
+			if doc.Format != ExportDocumentFormat || doc.Version != ExportDocumentVersion {
+				t.Errorf("format, version = %q, %d, want %q, %d", doc.Format, doc.Version, ExportDocumentFormat, ExportDocumentVersion)
+			}
+			if doc.ExportedAt.IsZero() {
+				t.Error("exported_at is not set")
+			}
+			if doc.Vocabularies == nil {
+				t.Error("vocabularies = null, want a list")
+			}
+			if len(doc.Vocabularies) != len(tt.vocabs) {
+				t.Fatalf("got %d vocabularies, want %d", len(doc.Vocabularies), len(tt.vocabs))
+			}
+			for i, vocab := range tt.vocabs {
+				if doc.Vocabularies[i].Word != vocab.Word {
+					t.Errorf("vocabularies[%d].word = %q, want %q", i, doc.Vocabularies[i].Word, vocab.Word)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package vocab
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vocabulary-app-be/internal/testutil"
+)
+
+// fakeRepository is an in-memory Repository with the semantics of the SQL repository for the
+// methods used by the tested service methods; the others are left to the embedded nil Repository.
+// Transactions run directly on the fake.
+type fakeRepository struct {
+	Repository
+
+	mu           sync.Mutex
+	ids          testutil.Sequence
+	vocabularies *testutil.Table[Vocabulary]
+	reviews      *testutil.Table[Review]
+	revisions    *testutil.Table[Revision]
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		vocabularies: testutil.NewTable[Vocabulary](),
+		reviews:      testutil.NewTable[Review](),
+		revisions:    testutil.NewTable[Revision](),
+	}
+}
+
+// add stores a vocabulary of the user with the SM-2 defaults of a new vocabulary
+func (r *fakeRepository) add(userID, word string, translation ...string) *Vocabulary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	vocab := Vocabulary{
+		ID:           r.ids.Next(),
+		UserID:       userID,
+		Word:         word,
+		Translation:  translation,
+		Tags:         Tags{},
+		Status:       StatusLearning,
+		EaseFactor:   DefaultEaseFactor,
+		NextReviewAt: time.Now(),
+	}
+	r.vocabularies.Insert(vocab.ID, vocab)
+	return &vocab
+}
+
+func (r *fakeRepository) WithTx(ctx context.Context, fn func(repo Repository) error) error {
+	return fn(r)
+}
+
+func (r *fakeRepository) FindByID(ctx context.Context, id string) (*Vocabulary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	vocab := r.vocabularies.Get(id)
+	if vocab == nil || vocab.DeletedAt != nil {
+		return nil, nil
+	}
+	return vocab, nil
+}
+
+func (r *fakeRepository) FindByIDForUpdate(ctx context.Context, id string) (*Vocabulary, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *fakeRepository) FindByIDsForUpdate(ctx context.Context, userID string, ids []string) ([]Vocabulary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var vocabularies []Vocabulary
+	for _, id := range ids {
+		if vocab := r.vocabularies.Get(id); vocab != nil && vocab.UserID == userID && vocab.DeletedAt == nil {
+			vocabularies = append(vocabularies, *vocab)
+		}
+	}
+	return vocabularies, nil
+}
+
+func (r *fakeRepository) Update(ctx context.Context, vocab *Vocabulary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	vocab.UpdatedAt = time.Now()
+	r.vocabularies.Insert(vocab.ID, *vocab)
+	return nil
+}
+
+func (r *fakeRepository) CreateReview(ctx context.Context, review *Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	review.ID = r.ids.Next()
+	review.CreatedAt = time.Now()
+	r.reviews.Insert(review.ID, *review)
+	return nil
+}
+
+func (r *fakeRepository) FindQuizSettings(ctx context.Context, userID string) (*QuizSettings, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) CreateRevision(ctx context.Context, revision *Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revision.ID = r.ids.Next()
+	revision.CreatedAt = time.Now()
+	r.revisions.Insert(revision.ID, *revision)
+	return nil
+}
@@ -0,0 +1,427 @@
+package vocab
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Import limits
+const (
+	// MaxImportRows is the maximum number of vocabulary rows in one import
+	MaxImportRows = 5000
+	// MaxImportFileSize is the maximum size of an uploaded import file in bytes
+	MaxImportFileSize = 5 << 20
+)
+
+// Column names accepted as headers when no explicit mapping is given
+var importHeaderAliases = map[string][]string{
+	"word":        {"word", "term"},
+	"definition":  {"definition", "meaning"},
+	"translation": {"translation", "translations"},
+	"example":     {"example", "examples"},
+	"tags":        {"tags", "tag", "decks", "deck"},
+}
+
+// Column positions used when the file has no header and no explicit mapping
+var importDefaultPositions = map[string]int{
+	"word":        0,
+	"translation": 1,
+	"definition":  2,
+	"example":     3,
+	"tags":        4,
+}
+
+// importFields lists the importable fields in column mapping order
+var importFields = []string{"word", "definition", "translation", "example", "tags"}
+
+// importColumns maps an importable field to its column index (-1 when absent)
+type importColumns map[string]int
+
+// parseImportCSV reads and validates vocabulary rows from a CSV file.
+// Valid rows and the errors of every invalid row are returned together.
+func parseImportCSV(r io.Reader, req *ImportRequest) ([]ImportRow, []ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if req.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(req.Delimiter)
+		if req.Delimiter == `\t` {
+			delimiter, size = '\t', len(req.Delimiter)
+		}
+		if size != len(req.Delimiter) {
+			return nil, nil, ErrInvalidImportOptions
+		}
+		reader.Comma = delimiter
+	}
+
+	separator := req.Separator
+	if separator == "" {
+		separator = "|"
+	}
+
+	hasHeader := req.HasHeader == nil || *req.HasHeader
+
+	var header []string
+	if hasHeader {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, nil, ErrEmptyImport
+		}
+		if err != nil {
+			return nil, []ImportRowError{csvRowError(err)}, nil
+		}
+		header = record
+	}
+
+	columns, err := resolveImportColumns(req, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []ImportRow
+	var rowErrors []ImportRowError
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// The reader cannot reliably continue after malformed quoting
+			rowErrors = append(rowErrors, csvRowError(err))
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) >= MaxImportRows {
+			return nil, nil, ErrImportTooLarge
+		}
+
+		rows = append(rows, ImportRow{
+			Row:         line,
+			Word:        strings.TrimSpace(columnValue(record, columns["word"])),
+			Definition:  strings.TrimSpace(columnValue(record, columns["definition"])),
+			Translation: Translations(splitValues(columnValue(record, columns["translation"]), separator)).Clean(),
+			Example:     Examples(splitValues(columnValue(record, columns["example"]), separator)),
+			Tags:        Tags(splitValues(columnValue(record, columns["tags"]), separator)).Clean(),
+		})
+	}
+
+	if len(rows) == 0 && len(rowErrors) == 0 {
+		return nil, nil, ErrEmptyImport
+	}
+
+	valid, errs := validateImportRows(rows)
+	return valid, append(rowErrors, errs...), nil
+}
+
+// parseImportJSON reads and validates vocabulary rows from a JSON export document.
+// Row numbers are positions in the vocabularies list starting at 1.
+func parseImportJSON(r io.Reader) ([]ImportRow, []ImportRowError, error) {
+	var doc ExportDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, ErrUnsupportedImportFile
+	}
+	if doc.Format != ExportDocumentFormat || doc.Version < 1 || doc.Version > ExportDocumentVersion {
+		return nil, nil, ErrUnsupportedImportFile
+	}
+	if len(doc.Vocabularies) == 0 {
+		return nil, nil, ErrEmptyImport
+	}
+	if len(doc.Vocabularies) > MaxImportRows {
+		return nil, nil, ErrImportTooLarge
+	}
+
+	rows := make([]ImportRow, 0, len(doc.Vocabularies))
+	for i := range doc.Vocabularies {
+		vocab := &doc.Vocabularies[i]
+		progress := vocab.LearningProgress
+		rows = append(rows, ImportRow{
+			Row:         i + 1,
+			Word:        strings.TrimSpace(vocab.Word),
+			Definition:  strings.TrimSpace(vocab.Definition),
+			Translation: vocab.Translation.Clean(),
+			Example:     vocab.Example,
+			Tags:        vocab.Tags.Clean(),
+			Lexical:     vocab.Lexical.Clean(),
+			Progress:    &progress,
+		})
+	}
+
+	valid, rowErrors := validateImportRows(rows)
+	return valid, rowErrors, nil
+}
+
+// resolveImportColumns maps each importable field to a column index using the
+// explicit mapping, then the header names, then the default column positions
+func resolveImportColumns(req *ImportRequest, header []string) (importColumns, error) {
+	mapping := map[string]string{
+		"word":        req.WordColumn,
+		"definition":  req.DefinitionColumn,
+		"translation": req.TranslationColumn,
+		"example":     req.ExampleColumn,
+		"tags":        req.TagsColumn,
+	}
+
+	headerIndex := make(map[string]int, len(header))
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, exists := headerIndex[key]; !exists {
+			headerIndex[key] = i
+		}
+	}
+
+	columns := make(importColumns, len(importFields))
+	for _, field := range importFields {
+		columns[field] = -1
+
+		if column := strings.TrimSpace(mapping[field]); column != "" {
+			if n, err := strconv.Atoi(column); err == nil {
+				if n < 1 {
+					return nil, ErrInvalidColumnMapping
+				}
+				columns[field] = n - 1
+				continue
+			}
+			i, ok := headerIndex[strings.ToLower(column)]
+			if !ok {
+				return nil, ErrInvalidColumnMapping
+			}
+			columns[field] = i
+			continue
+		}
+
+		if header == nil {
+			columns[field] = importDefaultPositions[field]
+			continue
+		}
+		for _, alias := range importHeaderAliases[field] {
+			if i, ok := headerIndex[alias]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+
+	if columns["word"] < 0 {
+		return nil, ErrInvalidColumnMapping
+	}
+
+	return columns, nil
+}
+
+// validateImportRows validates every row and rejects words repeated within the import.
+// It returns the valid rows and the errors of the invalid ones.
+func validateImportRows(rows []ImportRow) ([]ImportRow, []ImportRowError) {
+	var valid []ImportRow
+	var rowErrors []ImportRowError
+	firstSeen := make(map[string]int)
+
+	for i := range rows {
+		row := &rows[i]
+
+		errs := validateImportRow(row)
+		if first, ok := firstSeen[row.Word]; ok && row.Word != "" {
+			errs = append(errs, ImportRowError{Row: row.Row, Field: "word", Message: "duplicate word, first seen on row " + strconv.Itoa(first)})
+		} else if row.Word != "" {
+			firstSeen[row.Word] = row.Row
+		}
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+		valid = append(valid, *row)
+	}
+
+	return valid, rowErrors
+}
+
+// validateImportRow checks a parsed row against the vocabulary constraints
+func validateImportRow(row *ImportRow) []ImportRowError {
+	var errs []ImportRowError
+	add := func(field, message string) {
+		errs = append(errs, ImportRowError{Row: row.Row, Field: field, Message: message})
+	}
+
+	switch {
+	case row.Word == "":
+		add("word", "word is required")
+	case utf8.RuneCountInString(row.Word) > 255:
+		add("word", "word must be at most 255 characters")
+	}
+
+	if len(row.Tags) > 50 {
+		add("tags", "at most 50 tags are allowed")
+	}
+	for _, tag := range row.Tags {
+		if utf8.RuneCountInString(tag) > 100 {
+			add("tags", "tag \""+tag+"\" must be at most 100 characters")
+		}
+	}
+
+	switch err := row.Lexical.Validate(); err {
+	case ErrInvalidPartOfSpeech:
+		add("part_of_speech", "part of speech \""+string(row.Lexical.PartOfSpeech)+"\" is not supported")
+	case ErrInvalidGender:
+		add("gender", "gender must be masculine, feminine, neuter or common")
+	case ErrInvalidRegister:
+		add("register", "register \""+string(row.Lexical.Register)+"\" is not supported")
+	}
+	if utf8.RuneCountInString(row.Lexical.Pronunciation) > 255 {
+		add("pronunciation", "pronunciation must be at most 255 characters")
+	}
+
+	if p := row.Progress; p != nil {
+		if p.Status == "" {
+			p.Status = StatusLearning
+		}
+		if !p.Status.IsValid() {
+			add("status", "status must be learning or memorized")
+		}
+		if p.TestCount < 0 || p.PassedTestCount < 0 || p.FailedTestCount < 0 ||
+			p.ReverseTestCount < 0 || p.ReversePassedTestCount < 0 || p.ReverseFailedTestCount < 0 {
+			add("test_count", "test counters must not be negative")
+		}
+		if p.IntervalDays < 0 || p.Repetitions < 0 {
+			add("interval_days", "interval and repetitions must not be negative")
+		}
+		if p.EaseFactor < MinEaseFactor {
+			p.EaseFactor = MinEaseFactor
+		}
+		if p.NextReviewAt.IsZero() {
+			p.NextReviewAt = time.Now()
+		}
+	}
+
+	return errs
+}
+
+// csvRowError converts a CSV reader error into a row error
+func csvRowError(err error) ImportRowError {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return ImportRowError{Row: parseErr.StartLine, Message: parseErr.Err.Error()}
+	}
+	return ImportRowError{Message: err.Error()}
+}
+
+// columnValue returns the value of column i, or an empty string if the record has no such column
+func columnValue(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// splitValues splits a multi-value cell and drops empty values
+func splitValues(value, separator string) []string {
+	var values []string
+	for _, v := range strings.Split(value, separator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// isBlankRecord reports whether every field of a record is empty
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// applyImportRow updates the content of an existing vocabulary with an imported row and
+// reports whether anything changed. Learning progress is left to the caller.
+func applyImportRow(vocab *Vocabulary, row *ImportRow, strategy DuplicateStrategy) bool {
+	switch strategy {
+	case DuplicateOverwrite:
+		vocab.Definition = row.Definition
+		vocab.Translation = row.Translation
+		vocab.Example = row.Example
+		vocab.Tags = row.Tags
+		vocab.Lexical = row.Lexical
+		return true
+	case DuplicateMerge:
+		changed := mergeLexical(&vocab.Lexical, row.Lexical)
+		if vocab.Definition == "" && row.Definition != "" {
+			vocab.Definition = row.Definition
+			changed = true
+		}
+		if merged := append(append(Translations{}, vocab.Translation...), row.Translation...).Clean(); len(merged) != len(vocab.Translation) {
+			vocab.Translation = merged
+			changed = true
+		}
+		if merged := mergeExamples(vocab.Example, row.Example); len(merged) != len(vocab.Example) {
+			vocab.Example = merged
+			changed = true
+		}
+		if merged := append(append(Tags{}, vocab.Tags...), row.Tags...).Clean(); len(merged) != len(vocab.Tags) {
+			vocab.Tags = merged
+			changed = true
+		}
+		return changed
+	default:
+		return false
+	}
+}
+
+// mergeLexical fills in the lexical metadata missing from l and reports whether anything changed
+func mergeLexical(l *Lexical, added Lexical) bool {
+	changed := false
+	fill := func(current *string, value string) {
+		if *current == "" && value != "" {
+			*current = value
+			changed = true
+		}
+	}
+
+	partOfSpeech, gender, register := string(l.PartOfSpeech), string(l.Gender), string(l.Register)
+	fill(&partOfSpeech, string(added.PartOfSpeech))
+	fill(&l.Pronunciation, added.Pronunciation)
+	fill(&gender, string(added.Gender))
+	fill(&register, string(added.Register))
+	fill(&l.UsageNotes, added.UsageNotes)
+	l.PartOfSpeech, l.Gender, l.Register = PartOfSpeech(partOfSpeech), Gender(gender), Register(register)
+
+	for label, form := range added.Forms {
+		if _, ok := l.Forms.Get(label); !ok {
+			if l.Forms == nil {
+				l.Forms = Forms{}
+			}
+			l.Forms[label] = form
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// mergeExamples appends the examples that are not already present
+func mergeExamples(existing, added Examples) Examples {
+	merged := append(Examples{}, existing...)
+	seen := make(map[string]bool, len(existing))
+	for _, example := range existing {
+		seen[example] = true
+	}
+	for _, example := range added {
+		if !seen[example] {
+			seen[example] = true
+			merged = append(merged, example)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,272 @@
+package vocab
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	noHeader := false
+
+	tests := []struct {
+		name     string
+		input    string
+		req      ImportRequest
+		wantErr  error
+		wantRows []ImportRow
+		// wantErrors lists the row and field of each expected row error
+		wantErrors []ImportRowError
+	}{
+		{
+			name:  "header names",
+			input: "word,translation,definition,example,tags\nhouse,casa,a building,The house is old.,home\n",
+			wantRows: []ImportRow{
+				{Row: 2, Word: "house", Definition: "a building", Translation: Translations{"casa"}, Example: Examples{"The house is old."}, Tags: Tags{"home"}},
+			},
+		},
+		{
+			name:  "header aliases in any order and case",
+			input: "\ufeffTags,Meaning,Term,Translations\nhome,a building,house,casa\n",
+			wantRows: []ImportRow{
+				{Row: 2, Word: "house", Definition: "a building", Translation: Translations{"casa"}, Tags: Tags{"home"}},
+			},
+		},
+		{
+			name:  "multiple values",
+			input: "word,translation,example,tags\nhouse,casa| hogar ||,One.|Two.,home|buildings\n",
+			wantRows: []ImportRow{
+				{Row: 2, Word: "house", Translation: Translations{"casa", "hogar"}, Example: Examples{"One.", "Two."}, Tags: Tags{"home", "buildings"}},
+			},
+		},
+		{
+			name:  "custom delimiter and separator",
+			input: "word;translation\nhouse;casa,hogar\n",
+			req:   ImportRequest{Delimiter: ";", Separator: ","},
+			wantRows: []ImportRow{
+				{Row: 2, Word: "house", Translation: Translations{"casa", "hogar"}},
+			},
+		},
+		{
+			name:  "tab delimiter",
+			input: "word\ttranslation\nhouse\tcasa\n",
+			req:   ImportRequest{Delimiter: `\t`},
+			wantRows: []ImportRow{
+				{Row: 2, Word: "house", Translation: Translations{"casa"}},
+			},
+		},
+		{
+			name:  "default positions without header",
+			input: "house,casa,a building\ncat,gato\n",
+			req:   ImportRequest{HasHeader: &noHeader},
+			wantRows: []ImportRow{
+				{Row: 1, Word: "house", Definition: "a building", Translation: Translations{"casa"}},
+				{Row: 2, Word: "cat", Translation: Translations{"gato"}},
+			},
+		},
+		{
+			name:  "explicit mapping by name and number",
+			input: "front,back\nhouse,casa\n",
+			req:   ImportRequest{WordColumn: "Front", TranslationColumn: "2"},
+			wantRows: []ImportRow{
+				{Row: 2, Word: "house", Translation: Translations{"casa"}},
+			},
+		},
+		{
+			name:  "blank lines are skipped",
+			input: "word,translation\n\nhouse,casa\n , \n",
+			wantRows: []ImportRow{
+				{Row: 3, Word: "house", Translation: Translations{"casa"}},
+			},
+		},
+		{
+			name:    "header without a word column",
+			input:   "front,back\nhouse,casa\n",
+			wantErr: ErrInvalidColumnMapping,
+		},
+		{
+			name:    "mapping to an unknown column",
+			input:   "word,translation\nhouse,casa\n",
+			req:     ImportRequest{TranslationColumn: "meaning"},
+			wantErr: ErrInvalidColumnMapping,
+		},
+		{
+			name:    "mapping to column zero",
+			input:   "word,translation\nhouse,casa\n",
+			req:     ImportRequest{WordColumn: "0"},
+			wantErr: ErrInvalidColumnMapping,
+		},
+		{
+			name:    "multi-character delimiter",
+			input:   "word,translation\nhouse,casa\n",
+			req:     ImportRequest{Delimiter: ";;"},
+			wantErr: ErrInvalidImportOptions,
+		},
+		{
+			name:    "empty file",
+			input:   "",
+			wantErr: ErrEmptyImport,
+		},
+		{
+			name:    "header only",
+			input:   "word,translation\n",
+			wantErr: ErrEmptyImport,
+		},
+		{
+			name:  "missing word",
+			input: "word,translation\n,casa\ncat,gato\n",
+			wantRows: []ImportRow{
+				{Row: 3, Word: "cat", Translation: Translations{"gato"}},
+			},
+			wantErrors: []ImportRowError{{Row: 2, Field: "word"}},
+		},
+		{
+			name:       "word too long",
+			input:      "word\n" + strings.Repeat("a", 256) + "\n",
+			wantErrors: []ImportRowError{{Row: 2, Field: "word"}},
+		},
+		{
+			name:  "duplicate rows",
+			input: "word,translation\nhouse,casa\ncat,gato\nhouse,hogar\n",
+			wantRows: []ImportRow{
+				{Row: 2, Word: "house", Translation: Translations{"casa"}},
+				{Row: 3, Word: "cat", Translation: Translations{"gato"}},
+			},
+			wantErrors: []ImportRowError{{Row: 4, Field: "word"}},
+		},
+		{
+			name:  "malformed quoting stops reading",
+			input: "word,translation\nhouse,casa\n\"cat,gato\n",
+			wantRows: []ImportRow{
+				{Row: 2, Word: "house", Translation: Translations{"casa"}},
+			},
+			wantErrors: []ImportRowError{{Row: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			rows, rowErrors, err := parseImportCSV(strings.NewReader(tt.input), &req)
+			if err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(rows) != len(tt.wantRows) {
+				t.Fatalf("rows = %+v, want %+v", rows, tt.wantRows)
+			}
+			for i := range rows {
+				if !sameImportRow(rows[i], tt.wantRows[i]) {
+					t.Errorf("row %d = %+v, want %+v", i, rows[i], tt.wantRows[i])
+				}
+			}
+			if len(rowErrors) != len(tt.wantErrors) {
+				t.Fatalf("row errors = %+v, want %d", rowErrors, len(tt.wantErrors))
+			}
+			for i, want := range tt.wantErrors {
+				if got := rowErrors[i]; got.Row != want.Row || got.Field != want.Field || got.Message == "" {
+					t.Errorf("row error %d = %+v, want row %d field %q", i, got, want.Row, want.Field)
+				}
+			}
+		})
+	}
+}
+
+// sameImportRow compares the CSV fields of two rows, treating empty and missing lists alike
+func sameImportRow(a, b ImportRow) bool {
+	return a.Row == b.Row && a.Word == b.Word && a.Definition == b.Definition &&
+		slices.Equal(a.Translation, b.Translation) && slices.Equal(a.Example, b.Example) && slices.Equal(a.Tags, b.Tags)
+}
+
+func TestParseImportCSVDuplicateMessage(t *testing.T) {
+	_, rowErrors, err := parseImportCSV(strings.NewReader("word\nhouse\nhouse\n"), &ImportRequest{})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if len(rowErrors) != 1 || !strings.Contains(rowErrors[0].Message, "first seen on row 2") {
+		t.Errorf("row errors = %+v, want a duplicate of row 2", rowErrors)
+	}
+}
+
+func TestParseImportCSVMaxRows(t *testing.T) {
+	csvRows := func(n int) string {
+		var b strings.Builder
+		b.WriteString("word\n")
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "word%d\n", i)
+		}
+		return b.String()
+	}
+
+	rows, rowErrors, err := parseImportCSV(strings.NewReader(csvRows(MaxImportRows)), &ImportRequest{})
+	if err != nil || len(rowErrors) != 0 {
+		t.Fatalf("at the limit: error = %v, row errors = %v", err, rowErrors)
+	}
+	if len(rows) != MaxImportRows {
+		t.Errorf("at the limit: got %d rows, want %d", len(rows), MaxImportRows)
+	}
+
+	if _, _, err := parseImportCSV(strings.NewReader(csvRows(MaxImportRows+1)), &ImportRequest{}); err != ErrImportTooLarge {
+		t.Errorf("over the limit: error = %v, want %v", err, ErrImportTooLarge)
+	}
+}
+
+func TestParseImportJSON(t *testing.T) {
+	export := func(vocabs ...*Vocabulary) string {
+		var buf bytes.Buffer
+		w := newExportWriter(&buf, ExportFormatJSON)
+		for _, vocab := range vocabs {
+			if err := w.Write(vocab); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		return buf.String()
+	}
+
+	house := &Vocabulary{Word: "house", Translation: Translations{"casa"}, Status: StatusMemorized, EaseFactor: 2.7, Repetitions: 3}
+	rows, rowErrors, err := parseImportJSON(strings.NewReader(export(house, &Vocabulary{Word: "house"})))
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if len(rows) != 1 || rows[0].Word != "house" || rows[0].Progress == nil || rows[0].Progress.Repetitions != 3 {
+		t.Errorf("rows = %+v, want house with its progress", rows)
+	}
+	if len(rowErrors) != 1 || rowErrors[0].Row != 2 || rowErrors[0].Field != "word" {
+		t.Errorf("row errors = %+v, want the duplicate on row 2", rowErrors)
+	}
+
+	low := &Vocabulary{Word: "cat", EaseFactor: 0.4}
+	rows, _, err = parseImportJSON(strings.NewReader(export(low)))
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if len(rows) != 1 || rows[0].Progress.EaseFactor != MinEaseFactor {
+		t.Errorf("rows = %+v, want the ease factor clamped to %v", rows, MinEaseFactor)
+	}
+
+	invalid := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{"not json", "word,translation\n", ErrUnsupportedImportFile},
+		{"other format", `{"format":"other","version":1,"vocabularies":[{"word":"a"}]}`, ErrUnsupportedImportFile},
+		{"newer version", `{"format":"vocabulary-export","version":99,"vocabularies":[{"word":"a"}]}`, ErrUnsupportedImportFile},
+		{"no vocabularies", export(), ErrEmptyImport},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parseImportJSON(strings.NewReader(tt.input)); err != tt.wantErr {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
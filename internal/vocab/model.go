@@ -2,9 +2,14 @@ package vocab
 
 import (
 	"database/sql/driver"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"mime/multipart"
+	"strings"
 	"time"
+
+	"vocabulary-app-be/pkg/grading"
 )
 
 // Examples is a custom type for storing multiple examples as JSON
@@ -24,6 +29,128 @@ func (e Examples) Value() (driver.Value, error) {
 	return json.Marshal(e)
 }
 
+// Translations is a custom type for storing multiple accepted translations as JSON
+type Translations []string
+
+// Scan implements the sql.Scanner interface
+func (t *Translations) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, &t)
+}
+
+// Value implements the driver.Valuer interface
+func (t Translations) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// UnmarshalJSON accepts either a list of translations or a single translation string
+func (t *Translations) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Translations{single}.Clean()
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("translation must be a string or a list of strings")
+	}
+	*t = Translations(list).Clean()
+	return nil
+}
+
+// Clean trims translations and removes empty and duplicate entries
+func (t Translations) Clean() Translations {
+	cleaned := make(Translations, 0, len(t))
+	seen := make(map[string]bool, len(t))
+	for _, translation := range t {
+		translation = strings.TrimSpace(translation)
+		key := strings.ToLower(translation)
+		if translation == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, translation)
+	}
+	return cleaned
+}
+
+// Primary returns the first translation, or an empty string if there is none
+func (t Translations) Primary() string {
+	if len(t) == 0 {
+		return ""
+	}
+	return t[0]
+}
+
+// String joins all translations for display
+func (t Translations) String() string {
+	return strings.Join(t, ", ")
+}
+
+// Tags is a custom type for the names of the decks a vocabulary belongs to
+type Tags []string
+
+// Scan implements the sql.Scanner interface
+func (t *Tags) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, &t)
+}
+
+// Clean trims tag names and removes empty and duplicate (case-insensitive) entries
+func (t Tags) Clean() Tags {
+	return Tags(Translations(t).Clean())
+}
+
+// Has reports whether t contains tag (case-insensitive)
+func (t Tags) Has(tag string) bool {
+	return t.Shares(Tags{tag})
+}
+
+// Without returns the tags of t other than tag (case-insensitive)
+func (t Tags) Without(tag string) Tags {
+	tags := make(Tags, 0, len(t))
+	for _, existing := range t {
+		if !strings.EqualFold(existing, tag) {
+			tags = append(tags, existing)
+		}
+	}
+	return tags
+}
+
+// Renamed returns t with tag renamed to name (case-insensitive)
+func (t Tags) Renamed(tag, name string) Tags {
+	tags := make(Tags, len(t))
+	for i, existing := range t {
+		if strings.EqualFold(existing, tag) {
+			existing = name
+		}
+		tags[i] = existing
+	}
+	return tags
+}
+
+// Shares reports whether t and other have a tag in common (case-insensitive)
+func (t Tags) Shares(other Tags) bool {
+	for _, tag := range t {
+		for _, otherTag := range other {
+			if strings.EqualFold(tag, otherTag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Status represents the vocabulary learning status
 type Status string
 
@@ -42,97 +169,879 @@ func (s Status) String() string {
 	return string(s)
 }
 
+// PartOfSpeech represents the grammatical category of a vocabulary
+type PartOfSpeech string
+
+const (
+	PartOfSpeechNoun         PartOfSpeech = "noun"
+	PartOfSpeechVerb         PartOfSpeech = "verb"
+	PartOfSpeechAdjective    PartOfSpeech = "adjective"
+	PartOfSpeechAdverb       PartOfSpeech = "adverb"
+	PartOfSpeechPronoun      PartOfSpeech = "pronoun"
+	PartOfSpeechPreposition  PartOfSpeech = "preposition"
+	PartOfSpeechConjunction  PartOfSpeech = "conjunction"
+	PartOfSpeechDeterminer   PartOfSpeech = "determiner"
+	PartOfSpeechNumeral      PartOfSpeech = "numeral"
+	PartOfSpeechInterjection PartOfSpeech = "interjection"
+	PartOfSpeechParticle     PartOfSpeech = "particle"
+	PartOfSpeechPhrase       PartOfSpeech = "phrase"
+)
+
+// IsValid checks if the part of speech is valid; empty means unspecified
+func (p PartOfSpeech) IsValid() bool {
+	switch p {
+	case "", PartOfSpeechNoun, PartOfSpeechVerb, PartOfSpeechAdjective, PartOfSpeechAdverb, PartOfSpeechPronoun,
+		PartOfSpeechPreposition, PartOfSpeechConjunction, PartOfSpeechDeterminer, PartOfSpeechNumeral,
+		PartOfSpeechInterjection, PartOfSpeechParticle, PartOfSpeechPhrase:
+		return true
+	}
+	return false
+}
+
+// Gender represents the grammatical gender of a vocabulary
+type Gender string
+
+const (
+	GenderMasculine Gender = "masculine"
+	GenderFeminine  Gender = "feminine"
+	GenderNeuter    Gender = "neuter"
+	GenderCommon    Gender = "common"
+)
+
+// IsValid checks if the gender is valid; empty means unspecified
+func (g Gender) IsValid() bool {
+	return g == "" || g == GenderMasculine || g == GenderFeminine || g == GenderNeuter || g == GenderCommon
+}
+
+// Register represents the formality or style in which a vocabulary is used
+type Register string
+
+const (
+	RegisterNeutral    Register = "neutral"
+	RegisterFormal     Register = "formal"
+	RegisterInformal   Register = "informal"
+	RegisterColloquial Register = "colloquial"
+	RegisterSlang      Register = "slang"
+	RegisterLiterary   Register = "literary"
+	RegisterTechnical  Register = "technical"
+	RegisterArchaic    Register = "archaic"
+	RegisterVulgar     Register = "vulgar"
+)
+
+// IsValid checks if the register is valid; empty means unspecified
+func (r Register) IsValid() bool {
+	switch r {
+	case "", RegisterNeutral, RegisterFormal, RegisterInformal, RegisterColloquial, RegisterSlang,
+		RegisterLiterary, RegisterTechnical, RegisterArchaic, RegisterVulgar:
+		return true
+	}
+	return false
+}
+
+// Forms is a custom type for storing inflected or irregular forms as JSON, keyed by label
+// (e.g. "plural", "past", "past participle")
+type Forms map[string]string
+
+// Scan implements the sql.Scanner interface
+func (f *Forms) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, &f)
+}
+
+// Value implements the driver.Valuer interface
+func (f Forms) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(f)
+}
+
+// Clean trims labels and forms and removes entries where either is empty
+func (f Forms) Clean() Forms {
+	cleaned := make(Forms, len(f))
+	for label, form := range f {
+		label, form = strings.TrimSpace(label), strings.TrimSpace(form)
+		if label != "" && form != "" {
+			cleaned[label] = form
+		}
+	}
+	return cleaned
+}
+
+// Get returns the form with the given label (case-insensitive)
+func (f Forms) Get(label string) (string, bool) {
+	if form, ok := f[label]; ok {
+		return form, true
+	}
+	for key, form := range f {
+		if strings.EqualFold(key, label) {
+			return form, true
+		}
+	}
+	return "", false
+}
+
+// Lexical holds the optional structured metadata of a vocabulary
+type Lexical struct {
+	PartOfSpeech  PartOfSpeech `json:"part_of_speech,omitempty"`
+	Pronunciation string       `json:"pronunciation,omitempty" binding:"max=255"`
+	Gender        Gender       `json:"gender,omitempty"`
+	Forms         Forms        `json:"forms,omitempty" binding:"omitempty,max=20,dive,keys,max=50,endkeys,max=255"`
+	Register      Register     `json:"register,omitempty"`
+	UsageNotes    string       `json:"usage_notes,omitempty" binding:"max=2000"`
+}
+
+// Clean trims the free-text fields and forms
+func (l Lexical) Clean() Lexical {
+	l.Pronunciation = strings.TrimSpace(l.Pronunciation)
+	l.UsageNotes = strings.TrimSpace(l.UsageNotes)
+	l.Forms = l.Forms.Clean()
+	return l
+}
+
+// Validate checks the enumerated fields
+func (l Lexical) Validate() error {
+	switch {
+	case !l.PartOfSpeech.IsValid():
+		return ErrInvalidPartOfSpeech
+	case !l.Gender.IsValid():
+		return ErrInvalidGender
+	case !l.Register.IsValid():
+		return ErrInvalidRegister
+	}
+	return nil
+}
+
+// QuizMode represents the way a vocabulary was tested
+type QuizMode string
+
+const (
+	ModeTyping         QuizMode = "typing"
+	ModeMultipleChoice QuizMode = "multiple_choice"
+	// ModeDefinition shows the definition and asks for the word
+	ModeDefinition QuizMode = "definition"
+	// ModeCloze shows an example sentence with the word blanked out and asks for the word
+	ModeCloze QuizMode = "cloze"
+	// ModePronunciation shows the pronunciation and asks for the word
+	ModePronunciation QuizMode = "pronunciation"
+	// ModeGender shows the word and asks for its grammatical gender
+	ModeGender QuizMode = "gender"
+	// ModePartOfSpeech shows the word and asks for its part of speech
+	ModePartOfSpeech QuizMode = "part_of_speech"
+	// ModeForm shows the word and the label of one of its forms (e.g. "plural") and asks for that form
+	ModeForm QuizMode = "form"
+)
+
+// IsValid checks if the quiz mode is valid
+func (m QuizMode) IsValid() bool {
+	switch m {
+	case ModeTyping, ModeMultipleChoice, ModeDefinition, ModeCloze, ModePronunciation, ModeGender, ModePartOfSpeech, ModeForm:
+		return true
+	}
+	return false
+}
+
+// AsksForWord reports whether the mode always asks for the word, regardless of direction
+func (m QuizMode) AsksForWord() bool {
+	return m == ModeDefinition || m == ModeCloze || m == ModePronunciation
+}
+
+// AsksForMetadata reports whether the mode shows the word and asks for its lexical metadata
+func (m QuizMode) AsksForMetadata() bool {
+	return m == ModeGender || m == ModePartOfSpeech || m == ModeForm
+}
+
+// IgnoresDirection reports whether the mode always counts as a forward test
+func (m QuizMode) IgnoresDirection() bool {
+	return m.AsksForWord() || m.AsksForMetadata()
+}
+
+// Direction represents which side of a vocabulary is asked in a test
+type Direction string
+
+const (
+	// DirectionForward shows the word and asks for its translation
+	DirectionForward Direction = "forward"
+	// DirectionReverse shows the translation and asks for the word
+	DirectionReverse Direction = "reverse"
+)
+
+// IsValid checks if the direction is valid
+func (d Direction) IsValid() bool {
+	return d == DirectionForward || d == DirectionReverse
+}
+
 // Vocabulary represents the vocabulary domain model
 type Vocabulary struct {
-	ID                string    `json:"id"`
-	UserID            string    `json:"user_id"`
-	Word              string    `json:"word"`
-	Definition        string    `json:"definition"`
-	Example           Examples  `json:"example,omitempty"`
-	Translation       string    `json:"translation"`
-	Status            Status    `json:"status"`
-	TestCount         int64     `json:"test_count"`
-	PassedTestCount   int64     `json:"passed_test_count"`
-	FailedTestCount   int64     `json:"failed_test_count"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                     string       `json:"id"`
+	UserID                 string       `json:"user_id"`
+	Word                   string       `json:"word"`
+	Definition             string       `json:"definition"`
+	Example                Examples     `json:"example,omitempty"`
+	Translation            Translations `json:"translation"`
+	Tags                   Tags         `json:"tags"`
+	Status                 Status       `json:"status"`
+	TestCount              int64        `json:"test_count"`
+	PassedTestCount        int64        `json:"passed_test_count"`
+	FailedTestCount        int64        `json:"failed_test_count"`
+	ReverseTestCount       int64        `json:"reverse_test_count"`
+	ReversePassedTestCount int64        `json:"reverse_passed_test_count"`
+	ReverseFailedTestCount int64        `json:"reverse_failed_test_count"`
+	EaseFactor             float64      `json:"ease_factor"`
+	IntervalDays           int          `json:"interval_days"`
+	Repetitions            int          `json:"repetitions"`
+	NextReviewAt           time.Time    `json:"next_review_at"`
+	CreatedAt              time.Time    `json:"created_at"`
+	UpdatedAt              time.Time    `json:"updated_at"`
+	Lexical
+	// DeletedAt is set while the vocabulary is in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Highlight is an excerpt of the definition and examples with search matches
+	// wrapped in <mark> tags. It is only set on search results.
+	Highlight string `json:"highlight,omitempty"`
 }
 
 // CreateVocabRequest represents the create vocabulary request payload
 type CreateVocabRequest struct {
-	Word        string   `json:"word" binding:"required"`
-	Definition  string   `json:"definition"`
-	Example     Examples `json:"example"`
-	Translation string   `json:"translation"`
+	Word        string       `json:"word" binding:"required"`
+	Definition  string       `json:"definition"`
+	Example     Examples     `json:"example"`
+	Translation Translations `json:"translation"`
+	Tags        Tags         `json:"tags" binding:"omitempty,max=50,dive,max=100"`
+	Lexical
 }
 
 // UpdateVocabRequest represents the update vocabulary request payload
 type UpdateVocabRequest struct {
-	Word        string   `json:"word"`
-	Definition  string   `json:"definition"`
-	Example     Examples `json:"example"`
-	Translation string   `json:"translation"`
-	Status      Status   `json:"status"`
+	Word        string       `json:"word"`
+	Definition  string       `json:"definition"`
+	Example     Examples     `json:"example"`
+	Translation Translations `json:"translation"`
+	Tags        Tags         `json:"tags" binding:"omitempty,max=50,dive,max=100"`
+	Status      Status       `json:"status"`
+	// Lexical metadata is only changed when present; empty values clear it
+	PartOfSpeech  *PartOfSpeech `json:"part_of_speech"`
+	Pronunciation *string       `json:"pronunciation" binding:"omitempty,max=255"`
+	Gender        *Gender       `json:"gender"`
+	Forms         Forms         `json:"forms" binding:"omitempty,max=20,dive,keys,max=50,endkeys,max=255"`
+	Register      *Register     `json:"register"`
+	UsageNotes    *string       `json:"usage_notes" binding:"omitempty,max=2000"`
+}
+
+// ApplyLexical returns l with the lexical fields present in the request applied
+func (r *UpdateVocabRequest) ApplyLexical(l Lexical) Lexical {
+	if r.PartOfSpeech != nil {
+		l.PartOfSpeech = *r.PartOfSpeech
+	}
+	if r.Pronunciation != nil {
+		l.Pronunciation = *r.Pronunciation
+	}
+	if r.Gender != nil {
+		l.Gender = *r.Gender
+	}
+	if r.Forms != nil {
+		l.Forms = r.Forms
+	}
+	if r.Register != nil {
+		l.Register = *r.Register
+	}
+	if r.UsageNotes != nil {
+		l.UsageNotes = *r.UsageNotes
+	}
+	return l.Clean()
 }
 
-// TestResultRequest represents the test result update request (input-based validation)
+// TestResultRequest represents the answer to a free-text question (input-based validation).
+// Without a question token the input is graded against the translations. Practice answers
+// are graded only and do not count toward test results.
 type TestResultRequest struct {
-	Input string `json:"input" binding:"required"`
+	QuestionToken  string `json:"question_token"`
+	Input          string `json:"input" binding:"required,max=500"`
+	Practice       bool   `json:"practice"`
+	ResponseTimeMs *int64 `json:"response_time_ms" binding:"omitempty,min=0"`
+}
+
+// Review represents a single recorded test answer
+type Review struct {
+	ID             string        `json:"id"`
+	UserID         string        `json:"user_id"`
+	VocabularyID   string        `json:"vocabulary_id"`
+	QuestionID     *string       `json:"question_id,omitempty"`
+	Input          string        `json:"input"`
+	Passed         bool          `json:"passed"`
+	Grade          grading.Grade `json:"grade"`
+	Mode           QuizMode      `json:"mode"`
+	Direction      Direction     `json:"direction"`
+	ResponseTimeMs *int64        `json:"response_time_ms,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// ReviewHistoryResponse represents the paginated review history response
+type ReviewHistoryResponse struct {
+	Data       []Review `json:"data"`
+	Total      int64    `json:"total"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+	TotalPages int      `json:"total_pages"`
+}
+
+// RevisionSource is what caused a revision
+type RevisionSource string
+
+const (
+	RevisionCreate RevisionSource = "create"
+	RevisionUpdate RevisionSource = "update"
+	RevisionImport RevisionSource = "import"
+	RevisionBulk   RevisionSource = "bulk"
+	RevisionRevert RevisionSource = "revert"
+	RevisionDeck   RevisionSource = "deck"
+)
+
+// FieldChange holds the JSON values of a field before and after a revision
+type FieldChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// RevisionChanges maps changed field names to their change
+type RevisionChanges map[string]FieldChange
+
+// Scan implements the sql.Scanner interface
+func (c *RevisionChanges) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, &c)
 }
 
-// TestVocabulary represents vocabulary for testing (without answers)
+// Value implements the driver.Valuer interface
+func (c RevisionChanges) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Revision represents a recorded change to the content of a vocabulary
+type Revision struct {
+	ID           string          `json:"id"`
+	VocabularyID string          `json:"vocabulary_id"`
+	UserID       string          `json:"user_id"`
+	Source       RevisionSource  `json:"source"`
+	Changes      RevisionChanges `json:"changes"`
+	RevertedTo   *string         `json:"reverted_to,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// RevisionListResponse represents the paginated revision history response
+type RevisionListResponse struct {
+	Data       []Revision `json:"data"`
+	Total      int64      `json:"total"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	TotalPages int        `json:"total_pages"`
+}
+
+// TestFilter represents the criteria used to select vocabularies for testing
+type TestFilter struct {
+	Status    string
+	DueOnly   bool
+	Direction Direction
+	Mode      QuizMode
+	DeckID    string
+	Tag       string
+
+	PartOfSpeech PartOfSpeech
+}
+
+// ListFilter represents the criteria used to list and count vocabularies
+type ListFilter struct {
+	Search string
+	Status string
+	DeckID string
+	Tag    string
+	Sort   SortField
+	Order  SortOrder
+
+	PartOfSpeech PartOfSpeech
+	Gender       Gender
+	Register     Register
+}
+
+// SortField is a field vocabulary lists can be ordered by
+type SortField string
+
+const (
+	SortWord       SortField = "word"
+	SortCreatedAt  SortField = "created_at"
+	SortUpdatedAt  SortField = "updated_at"
+	SortTestCount  SortField = "test_count"
+	SortAccuracy   SortField = "accuracy"
+	SortNextReview SortField = "next_review"
+	// SortRelevance orders search results by rank and requires a search text
+	SortRelevance SortField = "relevance"
+)
+
+// IsValid checks if the sort field is valid
+func (f SortField) IsValid() bool {
+	switch f {
+	case SortWord, SortCreatedAt, SortUpdatedAt, SortTestCount, SortAccuracy, SortNextReview, SortRelevance:
+		return true
+	}
+	return false
+}
+
+// DefaultOrder returns the order used when none is given: alphabetical and
+// soonest review first, otherwise highest or newest first
+func (f SortField) DefaultOrder() SortOrder {
+	if f == SortWord || f == SortNextReview {
+		return SortAsc
+	}
+	return SortDesc
+}
+
+// SortOrder is the direction of a sort
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// IsValid checks if the sort order is valid
+func (o SortOrder) IsValid() bool {
+	return o == SortAsc || o == SortDesc
+}
+
+// ListCursor marks the position after the last vocabulary of a cursor page.
+// It is handed to clients as an opaque string.
+type ListCursor struct {
+	Sort  SortField `json:"s"`
+	Order SortOrder `json:"o"`
+	Key   string    `json:"k"`
+	ID    string    `json:"id"`
+}
+
+// Encode returns the opaque string form of the cursor
+func (c *ListCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeListCursor parses a cursor returned by Encode
+func DecodeListCursor(s string) (*ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor ListCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || !cursor.Sort.IsValid() || !cursor.Order.IsValid() || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// TestVocabulary represents vocabulary for testing (without answers).
+// Only the prompt is filled in: Word for forward tests, Translation for reverse tests,
+// Definition, Cloze or Pronunciation for definition, cloze and pronunciation tests,
+// and Word plus Form for form tests. Issued questions carry the token to answer them with.
 type TestVocabulary struct {
-	ID              string    `json:"id"`
-	UserID          string    `json:"user_id"`
-	Word            string    `json:"word"`
-	Status          Status    `json:"status"`
-	TestCount       int64     `json:"test_count"`
-	PassedTestCount int64     `json:"passed_test_count"`
-	FailedTestCount int64     `json:"failed_test_count"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-}
-
-// ToTestVocabulary converts Vocabulary to TestVocabulary (hides answers)
-func (v *Vocabulary) ToTestVocabulary() *TestVocabulary {
-	return &TestVocabulary{
-		ID:              v.ID,
-		UserID:          v.UserID,
-		Word:            v.Word,
-		Status:          v.Status,
-		TestCount:       v.TestCount,
-		PassedTestCount: v.PassedTestCount,
-		FailedTestCount: v.FailedTestCount,
-		CreatedAt:       v.CreatedAt,
-		UpdatedAt:       v.UpdatedAt,
-	}
-}
-
-// TestOption represents a single option for multiple choice tests (only translations)
+	ID                     string       `json:"id"`
+	UserID                 string       `json:"user_id"`
+	Word                   string       `json:"word,omitempty"`
+	Translation            Translations `json:"translation,omitempty"`
+	Definition             string       `json:"definition,omitempty"`
+	Cloze                  string       `json:"cloze,omitempty"`
+	Pronunciation          string       `json:"pronunciation,omitempty"`
+	Form                   string       `json:"form,omitempty"`
+	Mode                   QuizMode     `json:"mode,omitempty"`
+	Direction              Direction    `json:"direction,omitempty"`
+	QuestionToken          string       `json:"question_token,omitempty"`
+	ExpiresAt              *time.Time   `json:"expires_at,omitempty"`
+	Status                 Status       `json:"status"`
+	TestCount              int64        `json:"test_count"`
+	PassedTestCount        int64        `json:"passed_test_count"`
+	FailedTestCount        int64        `json:"failed_test_count"`
+	ReverseTestCount       int64        `json:"reverse_test_count"`
+	ReversePassedTestCount int64        `json:"reverse_passed_test_count"`
+	ReverseFailedTestCount int64        `json:"reverse_failed_test_count"`
+	IntervalDays           int          `json:"interval_days"`
+	NextReviewAt           time.Time    `json:"next_review_at"`
+	CreatedAt              time.Time    `json:"created_at"`
+	UpdatedAt              time.Time    `json:"updated_at"`
+}
+
+// ToTestVocabulary converts Vocabulary to TestVocabulary for the given direction (hides answers)
+func (v *Vocabulary) ToTestVocabulary(direction Direction) *TestVocabulary {
+	test := &TestVocabulary{
+		ID:                     v.ID,
+		UserID:                 v.UserID,
+		Direction:              DirectionForward,
+		Status:                 v.Status,
+		TestCount:              v.TestCount,
+		PassedTestCount:        v.PassedTestCount,
+		FailedTestCount:        v.FailedTestCount,
+		ReverseTestCount:       v.ReverseTestCount,
+		ReversePassedTestCount: v.ReversePassedTestCount,
+		ReverseFailedTestCount: v.ReverseFailedTestCount,
+		IntervalDays:           v.IntervalDays,
+		NextReviewAt:           v.NextReviewAt,
+		CreatedAt:              v.CreatedAt,
+		UpdatedAt:              v.UpdatedAt,
+	}
+
+	if direction == DirectionReverse {
+		test.Direction = DirectionReverse
+		test.Translation = v.Translation
+	} else {
+		test.Word = v.Word
+	}
+
+	return test
+}
+
+// TestOption represents a single option for multiple choice tests.
+// Options are primary translations for forward tests and words for reverse tests.
 type TestOption struct {
 	ID          string `json:"id"`
-	Translation string `json:"translation"`
+	Translation string `json:"translation,omitempty"`
+	Word        string `json:"word,omitempty"`
+}
+
+// TestOptions is a custom type for storing issued multiple-choice options as JSON
+type TestOptions []TestOption
+
+// Scan implements the sql.Scanner interface
+func (o *TestOptions) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, &o)
+}
+
+// Value implements the driver.Valuer interface
+func (o TestOptions) Value() (driver.Value, error) {
+	return json.Marshal(o)
 }
 
-// TestOptionsResponse represents multiple choice options response
+// TestQuestion represents an issued question, graded with the mode and direction it was issued for.
+// Multiple-choice questions hold their options; option IDs are opaque and only the server knows which one is correct.
+type TestQuestion struct {
+	ID              string      `json:"id"`
+	UserID          string      `json:"user_id"`
+	VocabularyID    string      `json:"vocabulary_id"`
+	Mode            QuizMode    `json:"mode"`
+	Direction       Direction   `json:"direction"`
+	FormLabel       string      `json:"form_label,omitempty"`
+	Cloze           string      `json:"cloze,omitempty"`
+	Options         TestOptions `json:"options"`
+	Practice        bool        `json:"practice"`
+	CorrectOptionID string      `json:"-"`
+	ExpiresAt       time.Time   `json:"expires_at"`
+	AnsweredAt      *time.Time  `json:"answered_at,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// TestOptionsResponse represents multiple choice options response.
+// Practice questions are padded with built-in distractors and do not count toward test results.
 type TestOptionsResponse struct {
-	Options []TestOption `json:"options"`
+	QuestionToken string       `json:"question_token"`
+	Options       []TestOption `json:"options"`
+	Practice      bool         `json:"practice"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+}
+
+// TestChoiceRequest represents the selection of a multiple-choice option
+type TestChoiceRequest struct {
+	QuestionToken  string `json:"question_token" binding:"required"`
+	OptionID       string `json:"option_id" binding:"required"`
+	ResponseTimeMs *int64 `json:"response_time_ms" binding:"omitempty,min=0"`
 }
 
 // TestResultResponse represents the test result response
 type TestResultResponse struct {
-	Passed        bool          `json:"passed"`
-	CorrectAnswer string        `json:"correct_answer,omitempty"`
-	Vocabulary    TestVocabulary `json:"vocabulary"`
+	Input           string                `json:"input"`
+	Passed          bool                  `json:"passed"`
+	Grade           grading.Grade         `json:"grade"`
+	CorrectAnswer   string                `json:"correct_answer,omitempty"`
+	CorrectOptionID string                `json:"correct_option_id,omitempty"`
+	Diff            []grading.DiffSegment `json:"diff,omitempty"`
+	Practice        bool                  `json:"practice"`
+	Vocabulary      TestVocabulary        `json:"vocabulary"`
+}
+
+// QuizSettings represents the per-user quiz preferences
+type QuizSettings struct {
+	UserID          string            `json:"user_id"`
+	AnswerTolerance grading.Tolerance `json:"answer_tolerance"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// UpdateQuizSettingsRequest represents the update quiz settings request payload
+type UpdateQuizSettingsRequest struct {
+	AnswerTolerance grading.Tolerance `json:"answer_tolerance" binding:"required"`
+}
+
+// DueReviewResponse represents the due review queue response
+type DueReviewResponse struct {
+	Data      []TestVocabulary `json:"data"`
+	Total     int64            `json:"total"`
+	Remaining int64            `json:"remaining"`
+	Limit     int              `json:"limit"`
 }
 
 // VocabListResponse represents the vocabulary list response
 type VocabListResponse struct {
+	Data []Vocabulary `json:"data"`
+	// Total is left out in cursor mode unless the count was asked for
+	Total      *int64    `json:"total,omitempty"`
+	Page       int       `json:"page"`
+	PageSize   int       `json:"page_size"`
+	TotalPages int       `json:"total_pages"`
+	Search     string    `json:"search,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	DeckID     string    `json:"deck,omitempty"`
+	Tag        string    `json:"tag,omitempty"`
+	Sort       SortField `json:"sort"`
+	Order      SortOrder `json:"order"`
+	// NextCursor fetches the following page in cursor mode; it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// TrashListResponse represents the list of vocabularies in the trash
+type TrashListResponse struct {
 	Data       []Vocabulary `json:"data"`
 	Total      int64        `json:"total"`
 	Page       int          `json:"page"`
 	PageSize   int          `json:"page_size"`
 	TotalPages int          `json:"total_pages"`
-	Search     string       `json:"search,omitempty"`
-	Status     string       `json:"status,omitempty"`
+}
+
+// DuplicateStrategy controls what an import does with words that already exist
+type DuplicateStrategy string
+
+const (
+	// DuplicateSkip keeps the existing vocabulary unchanged
+	DuplicateSkip DuplicateStrategy = "skip"
+	// DuplicateOverwrite replaces the existing content. The learning progress is kept
+	// unless the import asks to restore the progress saved in a JSON export.
+	DuplicateOverwrite DuplicateStrategy = "overwrite"
+	// DuplicateMerge fills empty fields and adds new translations, examples and tags
+	DuplicateMerge DuplicateStrategy = "merge"
+)
+
+// IsValid checks if the duplicate strategy is valid
+func (d DuplicateStrategy) IsValid() bool {
+	return d == DuplicateSkip || d == DuplicateOverwrite || d == DuplicateMerge
+}
+
+// ImportRequest represents the CSV or JSON import form.
+// Column fields name a CSV header (case-insensitive) or a 1-based column number.
+// RestoreProgress replaces the learning progress of overwritten vocabularies with the one
+// in a JSON export; it requires on_duplicate=overwrite.
+type ImportRequest struct {
+	File              *multipart.FileHeader `form:"file" binding:"required"`
+	Format            ExportFormat          `form:"format"`
+	OnDuplicate       DuplicateStrategy     `form:"on_duplicate"`
+	RestoreProgress   bool                  `form:"restore_progress"`
+	Delimiter         string                `form:"delimiter"`
+	Separator         string                `form:"separator"`
+	HasHeader         *bool                 `form:"has_header"`
+	DryRun            bool                  `form:"dry_run"`
+	WordColumn        string                `form:"word_column"`
+	DefinitionColumn  string                `form:"definition_column"`
+	TranslationColumn string                `form:"translation_column"`
+	ExampleColumn     string                `form:"example_column"`
+	TagsColumn        string                `form:"tags_column"`
+}
+
+// AnkiImportRequest represents the Anki package import form.
+// Field options name the note field mapped to each vocabulary field (case-insensitive).
+type AnkiImportRequest struct {
+	File             *multipart.FileHeader `form:"file" binding:"required"`
+	OnDuplicate      DuplicateStrategy     `form:"on_duplicate"`
+	DryRun           bool                  `form:"dry_run"`
+	WordField        string                `form:"word_field"`
+	DefinitionField  string                `form:"definition_field"`
+	TranslationField string                `form:"translation_field"`
+	ExampleField     string                `form:"example_field"`
+}
+
+// ImportRow represents a parsed and validated import row
+type ImportRow struct {
+	Row         int
+	Word        string
+	Definition  string
+	Translation Translations
+	Example     Examples
+	Tags        Tags
+	Lexical     Lexical
+	Progress    *LearningProgress
+}
+
+// ImportRowError describes why an import row is invalid
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult represents the outcome of an import
+type ImportResult struct {
+	Total   int              `json:"total"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Skipped int              `json:"skipped"`
+	DryRun  bool             `json:"dry_run"`
+	Errors  []ImportRowError `json:"errors,omitempty"`
+}
+
+// ExportFormat is the file format of an export or import
+type ExportFormat string
+
+const (
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatCSV  ExportFormat = "csv"
+)
+
+// IsValid checks if the export format is valid
+func (f ExportFormat) IsValid() bool {
+	return f == ExportFormatJSON || f == ExportFormatCSV
+}
+
+// JSON export document identification
+const (
+	ExportDocumentFormat  = "vocabulary-export"
+	ExportDocumentVersion = 1
+)
+
+// ExportDocument is the versioned JSON export of a collection, readable by the import
+type ExportDocument struct {
+	Format       string             `json:"format"`
+	Version      int                `json:"version"`
+	ExportedAt   time.Time          `json:"exported_at"`
+	Vocabularies []ExportVocabulary `json:"vocabularies"`
+}
+
+// LearningProgress is the learning state of a vocabulary
+type LearningProgress struct {
+	Status                 Status    `json:"status"`
+	TestCount              int64     `json:"test_count"`
+	PassedTestCount        int64     `json:"passed_test_count"`
+	FailedTestCount        int64     `json:"failed_test_count"`
+	ReverseTestCount       int64     `json:"reverse_test_count"`
+	ReversePassedTestCount int64     `json:"reverse_passed_test_count"`
+	ReverseFailedTestCount int64     `json:"reverse_failed_test_count"`
+	EaseFactor             float64   `json:"ease_factor"`
+	IntervalDays           int       `json:"interval_days"`
+	Repetitions            int       `json:"repetitions"`
+	NextReviewAt           time.Time `json:"next_review_at"`
+}
+
+// ExportVocabulary is a vocabulary in an export, without IDs
+type ExportVocabulary struct {
+	Word        string       `json:"word"`
+	Definition  string       `json:"definition"`
+	Translation Translations `json:"translation"`
+	Example     Examples     `json:"example"`
+	Tags        Tags         `json:"tags"`
+	Lexical
+	LearningProgress
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Progress returns the learning progress of the vocabulary
+func (v *Vocabulary) Progress() LearningProgress {
+	return LearningProgress{
+		Status:                 v.Status,
+		TestCount:              v.TestCount,
+		PassedTestCount:        v.PassedTestCount,
+		FailedTestCount:        v.FailedTestCount,
+		ReverseTestCount:       v.ReverseTestCount,
+		ReversePassedTestCount: v.ReversePassedTestCount,
+		ReverseFailedTestCount: v.ReverseFailedTestCount,
+		EaseFactor:             v.EaseFactor,
+		IntervalDays:           v.IntervalDays,
+		Repetitions:            v.Repetitions,
+		NextReviewAt:           v.NextReviewAt,
+	}
+}
+
+// ApplyProgress replaces the learning progress of the vocabulary
+func (v *Vocabulary) ApplyProgress(p LearningProgress) {
+	v.Status = p.Status
+	v.TestCount = p.TestCount
+	v.PassedTestCount = p.PassedTestCount
+	v.FailedTestCount = p.FailedTestCount
+	v.ReverseTestCount = p.ReverseTestCount
+	v.ReversePassedTestCount = p.ReversePassedTestCount
+	v.ReverseFailedTestCount = p.ReverseFailedTestCount
+	v.EaseFactor = p.EaseFactor
+	v.IntervalDays = p.IntervalDays
+	v.Repetitions = p.Repetitions
+	v.NextReviewAt = p.NextReviewAt
+}
+
+// ToExport converts Vocabulary to its export representation
+func (v *Vocabulary) ToExport() ExportVocabulary {
+	return ExportVocabulary{
+		Word:             v.Word,
+		Definition:       v.Definition,
+		Translation:      v.Translation,
+		Example:          v.Example,
+		Tags:             v.Tags,
+		Lexical:          v.Lexical,
+		LearningProgress: v.Progress(),
+		CreatedAt:        v.CreatedAt,
+		UpdatedAt:        v.UpdatedAt,
+	}
+}
+
+// BulkAction is an operation applied to many vocabularies at once
+type BulkAction string
+
+const (
+	BulkDelete        BulkAction = "delete"
+	BulkSetStatus     BulkAction = "set_status"
+	BulkAddTag        BulkAction = "add_tag"
+	BulkRemoveTag     BulkAction = "remove_tag"
+	// BulkResetCounters resets the test counters, status and SM-2 schedule, so the vocabulary is learned anew
+	BulkResetCounters BulkAction = "reset_counters"
+)
+
+// IsValid checks if the bulk action is valid
+func (a BulkAction) IsValid() bool {
+	switch a {
+	case BulkDelete, BulkSetStatus, BulkAddTag, BulkRemoveTag, BulkResetCounters:
+		return true
+	}
+	return false
+}
+
+// BulkRequest represents the bulk operation request payload.
+// Status is required for set_status and Tag for add_tag and remove_tag.
+type BulkRequest struct {
+	IDs    []string   `json:"ids" binding:"required,min=1,max=500,dive,required"`
+	Action BulkAction `json:"action" binding:"required"`
+	Status Status     `json:"status"`
+	Tag    string     `json:"tag" binding:"max=100"`
+}
+
+// BulkItemResult represents the outcome of a bulk operation for one vocabulary
+type BulkItemResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkResponse represents the outcome of a bulk operation
+type BulkResponse struct {
+	Action    BulkAction       `json:"action"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
 }
@@ -0,0 +1,69 @@
+package vocab
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestListCursorRoundTrip(t *testing.T) {
+	cursors := []ListCursor{
+		{Sort: SortWord, Order: SortAsc, Key: "house", ID: "4c4e7a32-3c1b-4a8e-9d65-0f5e6f1c2b3a"},
+		{Sort: SortCreatedAt, Order: SortDesc, Key: "2024-03-10T12:00:00Z", ID: "a"},
+		{Sort: SortWord, Order: SortDesc, Key: "", ID: "b"},
+	}
+
+	for _, cursor := range cursors {
+		encoded := cursor.Encode()
+		got, err := DecodeListCursor(encoded)
+		if err != nil {
+			t.Fatalf("DecodeListCursor(%q) error = %v", encoded, err)
+		}
+		if !reflect.DeepEqual(*got, cursor) {
+			t.Errorf("DecodeListCursor(%q) = %+v, want %+v", encoded, *got, cursor)
+		}
+	}
+}
+
+func TestDecodeListCursorInvalid(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "not a cursor!"},
+		{"not json", encode("word,asc,a,b")},
+		{"invalid sort", encode(`{"s":"color","o":"asc","k":"a","id":"b"}`)},
+		{"invalid order", encode(`{"s":"word","o":"up","k":"a","id":"b"}`)},
+		{"missing id", encode(`{"s":"word","o":"asc","k":"a"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeListCursor(tt.cursor); err != ErrInvalidCursor {
+				t.Errorf("DecodeListCursor(%q) error = %v, want %v", tt.cursor, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestTagsWithoutAndRenamed(t *testing.T) {
+	tags := Tags{"Animals", "Chapter 3", "animals"}
+
+	if got := tags.Without("ANIMALS"); !reflect.DeepEqual(got, Tags{"Chapter 3"}) {
+		t.Errorf("Without() = %v, want [Chapter 3]", got)
+	}
+	if got := tags.Renamed("chapter 3", "Chapter 4"); !reflect.DeepEqual(got, Tags{"Animals", "Chapter 4", "animals"}) {
+		t.Errorf("Renamed() = %v, want [Animals Chapter 4 animals]", got)
+	}
+	if !tags.Has("chapter 3") || tags.Has("Chapter") {
+		t.Errorf("Has() does not match whole tags case-insensitively")
+	}
+	if len(tags) != 3 || tags[1] != "Chapter 3" {
+		t.Errorf("tags changed to %v", tags)
+	}
+}
@@ -0,0 +1,124 @@
+package vocab
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ClozeBlank replaces the tested word in cloze sentences
+const ClozeBlank = "_____"
+
+// ToQuestion converts Vocabulary to a TestVocabulary prompt for the given quiz mode and direction (hides answers).
+// The form label of form tests and the sentence of cloze tests are stored with the issued question
+// and filled in by the caller.
+func (v *Vocabulary) ToQuestion(mode QuizMode, direction Direction) *TestVocabulary {
+	if mode.AsksForMetadata() {
+		test := v.ToTestVocabulary(DirectionForward)
+		test.Mode = mode
+		return test
+	}
+
+	if !mode.AsksForWord() {
+		test := v.ToTestVocabulary(direction)
+		test.Mode = mode
+		return test
+	}
+
+	test := v.ToTestVocabulary(DirectionForward)
+	test.Word = ""
+	test.Direction = ""
+	test.Mode = mode
+
+	switch mode {
+	case ModeDefinition:
+		test.Definition = v.Definition
+	case ModePronunciation:
+		test.Pronunciation = v.Pronunciation
+	}
+
+	return test
+}
+
+// supportsMode reports whether the vocabulary has the prompt and answer asked for by the quiz mode.
+// It mirrors the conditions FindForTest picks vocabularies with.
+func (v *Vocabulary) supportsMode(mode QuizMode) bool {
+	switch mode {
+	case ModeDefinition:
+		return v.Definition != ""
+	case ModeCloze:
+		return hasClozeExample(v.Word, v.Example)
+	case ModePronunciation:
+		return v.Pronunciation != ""
+	case ModeGender:
+		return v.Gender != ""
+	case ModePartOfSpeech:
+		return v.PartOfSpeech != ""
+	case ModeForm:
+		return len(v.Forms) > 0
+	default:
+		// Translations are the answer of forward tests and the prompt of reverse tests
+		return len(v.Translation) > 0
+	}
+}
+
+// hasClozeExample reports whether an example contains word, so buildCloze can blank it out
+func hasClozeExample(word string, examples Examples) bool {
+	if word == "" {
+		return false
+	}
+	for _, example := range examples {
+		if strings.Contains(strings.ToLower(example), strings.ToLower(word)) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomFormLabel picks the label of a random form, or returns an empty string if there are none
+func randomFormLabel(forms Forms) string {
+	labels := make([]string, 0, len(forms))
+	for label := range forms {
+		labels = append(labels, label)
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	sort.Strings(labels)
+	return labels[rand.IntN(len(labels))]
+}
+
+// buildCloze picks a random example containing word and blanks the word out of it.
+// Whole-word matches are preferred; an empty string is returned if no example contains the word.
+func buildCloze(word string, examples Examples) string {
+	if word == "" {
+		return ""
+	}
+
+	quoted := regexp.QuoteMeta(word)
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(^|[^\p{L}\p{N}])` + quoted + `([^\p{L}\p{N}]|$)`),
+		regexp.MustCompile(`(?i)` + quoted),
+	}
+
+	for i, pattern := range patterns {
+		var candidates []string
+		for _, example := range examples {
+			if pattern.MatchString(example) {
+				candidates = append(candidates, example)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		sentence := candidates[rand.IntN(len(candidates))]
+		if i == 0 {
+			return pattern.ReplaceAllString(sentence, "${1}"+ClozeBlank+"${2}")
+		}
+		return pattern.ReplaceAllString(sentence, ClozeBlank)
+	}
+
+	return ""
+}
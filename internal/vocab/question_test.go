@@ -0,0 +1,73 @@
+package vocab
+
+import "testing"
+
+func TestSupportsMode(t *testing.T) {
+	full := &Vocabulary{
+		Word:        "Haus",
+		Definition:  "a building for people to live in",
+		Translation: Translations{"house"},
+		Example:     Examples{"Das Haus ist alt."},
+		Lexical: Lexical{
+			PartOfSpeech:  PartOfSpeechNoun,
+			Pronunciation: "haʊ̯s",
+			Gender:        GenderNeuter,
+			Forms:         Forms{"plural": "Häuser"},
+		},
+	}
+	bare := &Vocabulary{Word: "Haus", Example: Examples{"Der Garten ist groß."}}
+
+	tests := []struct {
+		mode     QuizMode
+		wantFull bool
+		wantBare bool
+	}{
+		{ModeTyping, true, false},
+		{ModeMultipleChoice, true, false},
+		{ModeDefinition, true, false},
+		{ModeCloze, true, false},
+		{ModePronunciation, true, false},
+		{ModeGender, true, false},
+		{ModePartOfSpeech, true, false},
+		{ModeForm, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			if got := full.supportsMode(tt.mode); got != tt.wantFull {
+				t.Errorf("supportsMode(%s) with metadata = %v, want %v", tt.mode, got, tt.wantFull)
+			}
+			if got := bare.supportsMode(tt.mode); got != tt.wantBare {
+				t.Errorf("supportsMode(%s) without metadata = %v, want %v", tt.mode, got, tt.wantBare)
+			}
+		})
+	}
+}
+
+func TestBuildCloze(t *testing.T) {
+	tests := []struct {
+		name     string
+		word     string
+		examples Examples
+		want     string
+	}{
+		{"whole word", "cat", Examples{"The cat sleeps."}, "The " + ClozeBlank + " sleeps."},
+		{"case-insensitive", "cat", Examples{"Cat food"}, ClozeBlank + " food"},
+		{"whole word preferred", "cat", Examples{"A category", "My cat"}, "My " + ClozeBlank},
+		{"part of a word", "cat", Examples{"A category"}, "A " + ClozeBlank + "egory"},
+		{"no example", "cat", nil, ""},
+		{"word missing", "cat", Examples{"The dog sleeps."}, ""},
+		{"empty word", "", Examples{"The cat sleeps."}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildCloze(tt.word, tt.examples); got != tt.want {
+				t.Errorf("buildCloze(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+			if got := hasClozeExample(tt.word, tt.examples); got != (tt.want != "") {
+				t.Errorf("hasClozeExample(%q) = %v, want %v", tt.word, got, tt.want != "")
+			}
+		})
+	}
+}
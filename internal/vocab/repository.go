@@ -4,33 +4,171 @@ import (
 	"context"
 	"database/sql"
 	"strconv"
+	"strings"
+	"time"
+
+	"vocabulary-app-be/pkg/utils"
+
+	"github.com/lib/pq"
 )
 
 // Repository handles data access for vocabulary
 type Repository interface {
 	Create(ctx context.Context, vocab *Vocabulary) error
 	FindByID(ctx context.Context, id string) (*Vocabulary, error)
-	FindByUserID(ctx context.Context, userID string, page, pageSize int, search, status string) ([]Vocabulary, int64, error)
-	FindRandomByUserIDAndStatus(ctx context.Context, userID string, status string) (*Vocabulary, error)
-	FindRandomOptionsExcluding(ctx context.Context, userID string, excludeID string, count int) ([]Vocabulary, error)
-	CountByUserIDAndStatus(ctx context.Context, userID string, status string) (int64, error)
+	FindByIDForUpdate(ctx context.Context, id string) (*Vocabulary, error)
+	FindTrashedByID(ctx context.Context, id string) (*Vocabulary, error)
+	FindTrashedByUserID(ctx context.Context, userID string, page, pageSize int) ([]Vocabulary, int64, error)
+	FindByUserID(ctx context.Context, userID string, page, pageSize int, filter ListFilter) ([]Vocabulary, int64, error)
+	FindByUserIDAfter(ctx context.Context, userID string, after *ListCursor, limit int, filter ListFilter) ([]Vocabulary, *ListCursor, error)
+	FindByUserIDAndWords(ctx context.Context, userID string, words []string) ([]Vocabulary, error)
+	FindByIDsForUpdate(ctx context.Context, userID string, ids []string) ([]Vocabulary, error)
+	FindDeckName(ctx context.Context, userID, deckID string) (string, error)
+	FindDeckNameForUpdate(ctx context.Context, userID, deckID string) (string, error)
+	FindByDeckIDForUpdate(ctx context.Context, deckID string) ([]Vocabulary, error)
+	RenameDeck(ctx context.Context, deckID, name string) error
+	DeleteDeck(ctx context.Context, deckID string) error
+	FindAllByUserID(ctx context.Context, userID string, filter ListFilter) ([]Vocabulary, error)
+	EachByUserID(ctx context.Context, userID string, filter ListFilter, fn func(vocab *Vocabulary) error) error
+	FindRandomOptionsExcluding(ctx context.Context, userID string, excludeID string, partOfSpeech PartOfSpeech, count int) ([]Vocabulary, error)
+	FindForTest(ctx context.Context, userID string, filter TestFilter, limit int) ([]Vocabulary, error)
+	FindDueByUserID(ctx context.Context, userID string, limit int) ([]Vocabulary, error)
+	CountByUserID(ctx context.Context, userID string, filter ListFilter) (int64, error)
+	CountDueByUserID(ctx context.Context, userID string) (int64, error)
 	Update(ctx context.Context, vocab *Vocabulary) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
+	SetTags(ctx context.Context, vocab *Vocabulary, tags Tags) error
+	CreateRevision(ctx context.Context, revision *Revision) error
+	FindRevisionByID(ctx context.Context, id string) (*Revision, error)
+	FindRevisionsByVocabularyID(ctx context.Context, vocabID string, page, pageSize int) ([]Revision, int64, error)
+	FindRevisionsAfter(ctx context.Context, revision *Revision) ([]Revision, error)
+	CreateReview(ctx context.Context, review *Review) error
+	FindReviewByQuestionID(ctx context.Context, questionID string) (*Review, error)
+	FindReviewsByVocabularyID(ctx context.Context, vocabID string, page, pageSize int) ([]Review, int64, error)
+	CreateQuestion(ctx context.Context, question *TestQuestion) error
+	FindQuestionByID(ctx context.Context, id string) (*TestQuestion, error)
+	MarkQuestionAnswered(ctx context.Context, id string) (bool, error)
+	FindQuizSettings(ctx context.Context, userID string) (*QuizSettings, error)
+	UpsertQuizSettings(ctx context.Context, settings *QuizSettings) error
+	WithTx(ctx context.Context, fn func(repo Repository) error) error
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 type repository struct {
-	db *sql.DB
+	db   querier
+	conn *sql.DB
 }
 
 // NewRepository creates a new vocabulary repository
 func NewRepository(db *sql.DB) Repository {
-	return &repository{db: db}
+	return &repository{db: db, conn: db}
+}
+
+// WithTx runs fn with a repository bound to a single transaction.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+func (r *repository) WithTx(ctx context.Context, fn func(repo Repository) error) error {
+	// Already inside a transaction
+	if r.conn == nil {
+		return fn(r)
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&repository{db: tx}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// vocabColumns is the column list selected for a Vocabulary, in scanVocab order
+const vocabColumns = `id, user_id, word, COALESCE(definition, ''), COALESCE(example, '[]'::JSONB), COALESCE(translation, '[]'::JSONB), status, test_count, passed_test_count, failed_test_count, reverse_test_count, reverse_passed_test_count, reverse_failed_test_count, ease_factor, interval_days, repetitions, next_review_at, created_at, updated_at, deleted_at, part_of_speech, pronunciation, gender, forms, register, usage_notes, ` + tagsColumn
+
+// tagsColumn selects the names of the decks a vocabulary belongs to as a JSONB array
+const tagsColumn = `COALESCE((SELECT jsonb_agg(d.name ORDER BY LOWER(d.name)) FROM vocabulary_decks vd JOIN decks d ON d.id = vd.deck_id
+	WHERE vd.vocabulary_id = vocabularies.id), '[]'::JSONB)`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanVocab scans a row selected with vocabColumns into a Vocabulary.
+// Extra destinations receive any columns selected after vocabColumns.
+func scanVocab(row rowScanner, vocab *Vocabulary, extra ...any) error {
+	dest := []any{
+		&vocab.ID,
+		&vocab.UserID,
+		&vocab.Word,
+		&vocab.Definition,
+		&vocab.Example,
+		&vocab.Translation,
+		&vocab.Status,
+		&vocab.TestCount,
+		&vocab.PassedTestCount,
+		&vocab.FailedTestCount,
+		&vocab.ReverseTestCount,
+		&vocab.ReversePassedTestCount,
+		&vocab.ReverseFailedTestCount,
+		&vocab.EaseFactor,
+		&vocab.IntervalDays,
+		&vocab.Repetitions,
+		&vocab.NextReviewAt,
+		&vocab.CreatedAt,
+		&vocab.UpdatedAt,
+		&vocab.DeletedAt,
+		&vocab.PartOfSpeech,
+		&vocab.Pronunciation,
+		&vocab.Gender,
+		&vocab.Forms,
+		&vocab.Register,
+		&vocab.UsageNotes,
+		&vocab.Tags,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// scanVocabRows scans all rows selected with vocabColumns
+func scanVocabRows(rows *sql.Rows) ([]Vocabulary, error) {
+	defer rows.Close()
+
+	var vocabularies []Vocabulary
+	for rows.Next() {
+		var vocab Vocabulary
+		if err := scanVocab(rows, &vocab); err != nil {
+			return nil, err
+		}
+		vocabularies = append(vocabularies, vocab)
+	}
+
+	return vocabularies, rows.Err()
 }
 
 // Create creates a new vocabulary entry
 func (r *repository) Create(ctx context.Context, vocab *Vocabulary) error {
-	query := `INSERT INTO vocabularies (user_id, word, definition, example, translation, status, test_count, passed_test_count, failed_test_count, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW()) RETURNING id`
+	query := `INSERT INTO vocabularies (user_id, word, definition, example, translation, status, test_count, passed_test_count, failed_test_count,
+			  reverse_test_count, reverse_passed_test_count, reverse_failed_test_count, ease_factor, interval_days, repetitions, next_review_at,
+			  part_of_speech, pronunciation, gender, forms, register, usage_notes, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, COALESCE($16, NOW()), $17, $18, $19, $20, $21, $22, NOW(), NOW())
+			  RETURNING id, next_review_at, created_at, updated_at`
+
+	// New vocabularies are due immediately unless a review time is given (imports)
+	var nextReviewAt sql.NullTime
+	if !vocab.NextReviewAt.IsZero() {
+		nextReviewAt = sql.NullTime{Time: vocab.NextReviewAt, Valid: true}
+	}
 
 	return r.db.QueryRowContext(ctx, query,
 		vocab.UserID,
@@ -42,30 +180,44 @@ func (r *repository) Create(ctx context.Context, vocab *Vocabulary) error {
 		vocab.TestCount,
 		vocab.PassedTestCount,
 		vocab.FailedTestCount,
-	).Scan(&vocab.ID)
+		vocab.ReverseTestCount,
+		vocab.ReversePassedTestCount,
+		vocab.ReverseFailedTestCount,
+		vocab.EaseFactor,
+		vocab.IntervalDays,
+		vocab.Repetitions,
+		nextReviewAt,
+		vocab.PartOfSpeech,
+		vocab.Pronunciation,
+		vocab.Gender,
+		vocab.Forms,
+		vocab.Register,
+		vocab.UsageNotes,
+	).Scan(&vocab.ID, &vocab.NextReviewAt, &vocab.CreatedAt, &vocab.UpdatedAt)
 }
 
-// FindByID finds a vocabulary by ID
+// FindByID finds a vocabulary by ID, ignoring vocabularies in the trash
 func (r *repository) FindByID(ctx context.Context, id string) (*Vocabulary, error) {
-	query := `SELECT id, user_id, word, definition, example, translation, status, test_count, passed_test_count, failed_test_count, created_at, updated_at 
-			  FROM vocabularies WHERE id = $1`
+	query := `SELECT ` + vocabColumns + ` FROM vocabularies WHERE id = $1 AND deleted_at IS NULL`
 
 	var vocab Vocabulary
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&vocab.ID,
-		&vocab.UserID,
-		&vocab.Word,
-		&vocab.Definition,
-		&vocab.Example,
-		&vocab.Translation,
-		&vocab.Status,
-		&vocab.TestCount,
-		&vocab.PassedTestCount,
-		&vocab.FailedTestCount,
-		&vocab.CreatedAt,
-		&vocab.UpdatedAt,
-	)
-	if err != nil {
+	if err := scanVocab(r.db.QueryRowContext(ctx, query, id), &vocab); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &vocab, nil
+}
+
+// FindByIDForUpdate finds a vocabulary by ID like FindByID and locks its row until the
+// transaction ends. It must be called on a repository passed to WithTx.
+func (r *repository) FindByIDForUpdate(ctx context.Context, id string) (*Vocabulary, error) {
+	query := `SELECT ` + vocabColumns + ` FROM vocabularies WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+
+	var vocab Vocabulary
+	if err := scanVocab(r.db.QueryRowContext(ctx, query, id), &vocab); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -75,25 +227,210 @@ func (r *repository) FindByID(ctx context.Context, id string) (*Vocabulary, erro
 	return &vocab, nil
 }
 
-// FindByUserID finds vocabularies by user ID with pagination, search, and status filter
-func (r *repository) FindByUserID(ctx context.Context, userID string, page, pageSize int, search, status string) ([]Vocabulary, int64, error) {
+// searchQuery is the full-text query of the search text bound to $2
+const searchQuery = `websearch_to_tsquery('simple', $2)`
+
+// searchCondition matches the search text bound to $2 and its ILIKE pattern bound to $3.
+// Full-text matches cover all fields including examples; substring and trigram matches on
+// word and translation catch partial words and misspellings. Translations are matched as the
+// lines of vocabulary_translation_text, which is trigram indexed, rather than the JSON text so
+// quotes and separators do not match.
+const searchCondition = `(search_vector @@ ` + searchQuery + `
+	OR word ILIKE $3 OR word % $2
+	OR vocabulary_translation_text(translation) ILIKE $3 OR $2 <% vocabulary_translation_text(translation))`
+
+// searchRank ranks search results by full-text relevance and spelling similarity of the word
+const searchRank = `ts_rank(search_vector, ` + searchQuery + `) + similarity(word, $2)`
+
+// searchHighlight selects an excerpt of the definition and examples with full-text matches marked
+const searchHighlight = `CASE WHEN search_vector @@ ` + searchQuery + ` THEN ts_headline('simple',
+	CONCAT_WS(' ... ', NULLIF(definition, ''), (SELECT string_agg(e.sentence, ' ... ') FROM jsonb_array_elements_text(COALESCE(example, '[]'::JSONB)) AS e(sentence))),
+	` + searchQuery + `, 'StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=" ... "') ELSE '' END`
+
+// sortExpressions maps sort fields to the expressions they order by.
+// Test count and accuracy cover both test directions.
+var sortExpressions = map[SortField]string{
+	SortWord:       "LOWER(word)",
+	SortCreatedAt:  "created_at",
+	SortUpdatedAt:  "updated_at",
+	SortTestCount:  "(test_count + reverse_test_count)",
+	SortAccuracy:   "(CASE WHEN test_count + reverse_test_count = 0 THEN 0 ELSE (passed_test_count + reverse_passed_test_count)::FLOAT8 / (test_count + reverse_test_count) END)",
+	SortNextReview: "next_review_at",
+	SortRelevance:  "(" + searchRank + ")",
+}
+
+// sortKeyTypes maps sort fields to the SQL type of their expression, used to compare cursor keys
+var sortKeyTypes = map[SortField]string{
+	SortWord:       "TEXT",
+	SortCreatedAt:  "TIMESTAMP",
+	SortUpdatedAt:  "TIMESTAMP",
+	SortTestCount:  "BIGINT",
+	SortAccuracy:   "FLOAT8",
+	SortNextReview: "TIMESTAMPTZ",
+	SortRelevance:  "REAL",
+}
+
+// sortKey returns the expression rendering the cursor key of a sort field as text.
+// Time zone aware keys are written in UTC so they do not depend on the session time zone.
+func sortKey(field SortField) string {
+	if sortKeyTypes[field] == "TIMESTAMPTZ" {
+		return `TO_CHAR(` + sortExpressions[field] + ` AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')`
+	}
+	return `(` + sortExpressions[field] + `)::TEXT`
+}
+
+// orderClause builds the ORDER BY clause of filter's sort; the ID breaks ties so the order is stable
+func orderClause(filter ListFilter) string {
+	direction := "ASC"
+	if filter.Order == SortDesc {
+		direction = "DESC"
+	}
+	return sortExpressions[filter.Sort] + " " + direction + ", id " + direction
+}
+
+// listColumns selects vocabColumns followed by the search highlight, empty when not searching
+func listColumns(filter ListFilter) string {
+	if filter.Search != "" {
+		return vocabColumns + `, ` + searchHighlight
+	}
+	return vocabColumns + `, ''`
+}
+
+// listCondition builds the WHERE condition and arguments selecting a user's vocabularies matching filter.
+// When filter.Search is set, the search text is bound to $2.
+func listCondition(userID string, filter ListFilter) (string, []any, int) {
 	// Build dynamic query conditions
-	baseCondition := "user_id = $1"
+	condition := "user_id = $1 AND deleted_at IS NULL"
 	args := []any{userID}
 	argIndex := 2
 
-	if search != "" {
-		baseCondition += " AND (word ILIKE $" + itoa(argIndex) + " OR translation ILIKE $" + itoa(argIndex) + " OR definition ILIKE $" + itoa(argIndex) + ")"
-		args = append(args, "%"+search+"%")
+	if filter.Search != "" {
+		condition += " AND " + searchCondition
+		args = append(args, filter.Search, "%"+escapeLike(filter.Search)+"%")
+		argIndex += 2
+	}
+
+	if filter.Status != "" && filter.Status != "all" {
+		condition += " AND status = $" + itoa(argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	condition, args, argIndex = appendLexicalCondition(condition, args, argIndex, filter.PartOfSpeech, filter.Gender, filter.Register)
+
+	return appendDeckCondition(condition, args, argIndex, filter.DeckID, filter.Tag)
+}
+
+// appendLexicalCondition restricts condition to vocabularies with the given lexical metadata; empty values match all
+func appendLexicalCondition(condition string, args []any, argIndex int, partOfSpeech PartOfSpeech, gender Gender, register Register) (string, []any, int) {
+	for _, field := range []struct {
+		column string
+		value  string
+	}{
+		{"part_of_speech", string(partOfSpeech)},
+		{"gender", string(gender)},
+		{"register", string(register)},
+	} {
+		if field.value != "" {
+			condition += " AND " + field.column + " = $" + itoa(argIndex)
+			args = append(args, field.value)
+			argIndex++
+		}
+	}
+
+	return condition, args, argIndex
+}
+
+// appendDeckCondition restricts condition to vocabularies in the deck with the given ID and/or name.
+// The user ID must be bound to $1.
+func appendDeckCondition(condition string, args []any, argIndex int, deckID, tag string) (string, []any, int) {
+	if deckID != "" && !utils.IsUUID(deckID) {
+		// A malformed deck ID matches no deck instead of failing the query
+		condition += " AND FALSE"
+	} else if deckID != "" {
+		condition += " AND id IN (SELECT vd.vocabulary_id FROM vocabulary_decks vd WHERE vd.deck_id = $" + itoa(argIndex) + "::UUID)"
+		args = append(args, deckID)
 		argIndex++
 	}
 
-	if status != "" && status != "all" {
-		baseCondition += " AND status = $" + itoa(argIndex)
-		args = append(args, status)
+	if tag != "" {
+		condition += " AND id IN (SELECT vd.vocabulary_id FROM vocabulary_decks vd JOIN decks d ON d.id = vd.deck_id" +
+			" WHERE d.user_id = $1 AND LOWER(d.name) = LOWER($" + itoa(argIndex) + "))"
+		args = append(args, tag)
 		argIndex++
 	}
 
+	return condition, args, argIndex
+}
+
+// FindDeckName finds the name of a user's deck, returning an empty string if there is no such deck
+func (r *repository) FindDeckName(ctx context.Context, userID, deckID string) (string, error) {
+	return r.findDeckName(ctx, `SELECT name FROM decks WHERE id = $1::UUID AND user_id = $2`, userID, deckID)
+}
+
+// FindDeckNameForUpdate is FindDeckName locking the deck until the transaction ends.
+// It must be called on a repository passed to WithTx.
+func (r *repository) FindDeckNameForUpdate(ctx context.Context, userID, deckID string) (string, error) {
+	return r.findDeckName(ctx, `SELECT name FROM decks WHERE id = $1::UUID AND user_id = $2 FOR UPDATE`, userID, deckID)
+}
+
+// findDeckName runs a query selecting the name of the deck with ID $1 and user ID $2
+func (r *repository) findDeckName(ctx context.Context, query, userID, deckID string) (string, error) {
+	if !utils.IsUUID(deckID) {
+		return "", nil
+	}
+
+	var name string
+	if err := r.db.QueryRowContext(ctx, query, deckID, userID).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return name, nil
+}
+
+// FindByDeckIDForUpdate finds the vocabularies in a deck, including those in the trash, and locks them
+// until the transaction ends. It must be called on a repository passed to WithTx.
+func (r *repository) FindByDeckIDForUpdate(ctx context.Context, deckID string) ([]Vocabulary, error) {
+	query := `SELECT ` + vocabColumns + ` FROM vocabularies
+			  WHERE id IN (SELECT vocabulary_id FROM vocabulary_decks WHERE deck_id = $1::UUID)
+			  ORDER BY id FOR UPDATE`
+
+	rows, err := r.db.QueryContext(ctx, query, deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanVocabRows(rows)
+}
+
+// RenameDeck renames a deck, which renames the tag of every vocabulary in it.
+// A violation of the unique deck name index is returned as is.
+func (r *repository) RenameDeck(ctx context.Context, deckID, name string) error {
+	query := `UPDATE decks SET name = $1, updated_at = NOW() WHERE id = $2::UUID`
+	_, err := r.db.ExecContext(ctx, query, name, deckID)
+	return err
+}
+
+// DeleteDeck deletes a deck, which removes its tag from every vocabulary in it
+func (r *repository) DeleteDeck(ctx context.Context, deckID string) error {
+	query := `DELETE FROM decks WHERE id = $1::UUID`
+	_, err := r.db.ExecContext(ctx, query, deckID)
+	return err
+}
+
+// escapeLike escapes the wildcard characters of an ILIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// FindByUserID finds vocabularies by user ID with pagination, search, status and deck filters,
+// ordered by filter's sort. Search results carry a highlighted excerpt.
+func (r *repository) FindByUserID(ctx context.Context, userID string, page, pageSize int, filter ListFilter) ([]Vocabulary, int64, error) {
+	baseCondition, args, argIndex := listCondition(userID, filter)
+
 	// Get total count
 	countQuery := "SELECT COUNT(*) FROM vocabularies WHERE " + baseCondition
 	var total int64
@@ -103,9 +440,9 @@ func (r *repository) FindByUserID(ctx context.Context, userID string, page, page
 
 	// Get paginated results
 	offset := (page - 1) * pageSize
-	query := `SELECT id, user_id, word, definition, example, translation, status, test_count, passed_test_count, failed_test_count, created_at, updated_at 
-			  FROM vocabularies WHERE ` + baseCondition + ` 
-			  ORDER BY created_at DESC LIMIT $` + itoa(argIndex) + ` OFFSET $` + itoa(argIndex+1)
+	query := `SELECT ` + listColumns(filter) + `
+			  FROM vocabularies WHERE ` + baseCondition + `
+			  ORDER BY ` + orderClause(filter) + ` LIMIT $` + itoa(argIndex) + ` OFFSET $` + itoa(argIndex+1)
 	args = append(args, pageSize, offset)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -117,28 +454,148 @@ func (r *repository) FindByUserID(ctx context.Context, userID string, page, page
 	var vocabularies []Vocabulary
 	for rows.Next() {
 		var vocab Vocabulary
-		if err := rows.Scan(
-			&vocab.ID,
-			&vocab.UserID,
-			&vocab.Word,
-			&vocab.Definition,
-			&vocab.Example,
-			&vocab.Translation,
-			&vocab.Status,
-			&vocab.TestCount,
-			&vocab.PassedTestCount,
-			&vocab.FailedTestCount,
-			&vocab.CreatedAt,
-			&vocab.UpdatedAt,
-		); err != nil {
+		if err := scanVocab(rows, &vocab, &vocab.Highlight); err != nil {
 			return nil, 0, err
 		}
 		vocabularies = append(vocabularies, vocab)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
 	return vocabularies, total, nil
 }
 
+// FindByUserIDAfter finds up to limit vocabularies following the after cursor (nil for the first page)
+// in filter's sort order. The returned cursor points past the last vocabulary, or is nil when there are no more.
+func (r *repository) FindByUserIDAfter(ctx context.Context, userID string, after *ListCursor, limit int, filter ListFilter) ([]Vocabulary, *ListCursor, error) {
+	condition, args, argIndex := listCondition(userID, filter)
+	expression := sortExpressions[filter.Sort]
+
+	if after != nil {
+		comparison := ">"
+		if filter.Order == SortDesc {
+			comparison = "<"
+		}
+		condition += " AND (" + expression + ", id) " + comparison +
+			" ($" + itoa(argIndex) + "::" + sortKeyTypes[filter.Sort] + ", $" + itoa(argIndex+1) + "::UUID)"
+		args = append(args, after.Key, after.ID)
+		argIndex += 2
+	}
+
+	// Fetch one extra row to know whether another page follows
+	query := `SELECT ` + listColumns(filter) + `, ` + sortKey(filter.Sort) + `
+			  FROM vocabularies WHERE ` + condition + `
+			  ORDER BY ` + orderClause(filter) + ` LIMIT $` + itoa(argIndex)
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var vocabularies []Vocabulary
+	var keys []string
+	for rows.Next() {
+		var vocab Vocabulary
+		var key string
+		if err := scanVocab(rows, &vocab, &vocab.Highlight, &key); err != nil {
+			return nil, nil, err
+		}
+		vocabularies = append(vocabularies, vocab)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(vocabularies) <= limit {
+		return vocabularies, nil, nil
+	}
+
+	vocabularies = vocabularies[:limit]
+	return vocabularies, &ListCursor{
+		Sort:  filter.Sort,
+		Order: filter.Order,
+		Key:   keys[limit-1],
+		ID:    vocabularies[limit-1].ID,
+	}, nil
+}
+
+// FindAllByUserID finds all vocabularies of a user matching filter, ordered by word
+func (r *repository) FindAllByUserID(ctx context.Context, userID string, filter ListFilter) ([]Vocabulary, error) {
+	condition, args, _ := listCondition(userID, filter)
+	query := `SELECT ` + vocabColumns + ` FROM vocabularies WHERE ` + condition + ` ORDER BY LOWER(word) ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanVocabRows(rows)
+}
+
+// EachByUserID calls fn for every vocabulary of a user matching filter, ordered by word,
+// without loading them all into memory. It stops at the first error returned by fn.
+func (r *repository) EachByUserID(ctx context.Context, userID string, filter ListFilter, fn func(vocab *Vocabulary) error) error {
+	condition, args, _ := listCondition(userID, filter)
+	query := `SELECT ` + vocabColumns + ` FROM vocabularies WHERE ` + condition + ` ORDER BY LOWER(word) ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vocab Vocabulary
+		if err := scanVocab(rows, &vocab); err != nil {
+			return err
+		}
+		if err := fn(&vocab); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// FindByIDsForUpdate finds the user's vocabularies outside the trash with any of the given IDs
+// and locks their rows until the transaction ends; malformed IDs match nothing.
+// Rows are locked in ID order so concurrent calls cannot deadlock. It must be called on a repository passed to WithTx.
+func (r *repository) FindByIDsForUpdate(ctx context.Context, userID string, ids []string) ([]Vocabulary, error) {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if utils.IsUUID(id) {
+			valid = append(valid, id)
+		}
+	}
+
+	query := `SELECT ` + vocabColumns + ` FROM vocabularies
+			  WHERE id = ANY($1::UUID[]) AND user_id = $2 AND deleted_at IS NULL
+			  ORDER BY id FOR UPDATE`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(valid), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanVocabRows(rows)
+}
+
+// FindByUserIDAndWords finds the user's vocabularies outside the trash with any of the given words
+func (r *repository) FindByUserIDAndWords(ctx context.Context, userID string, words []string) ([]Vocabulary, error) {
+	query := `SELECT ` + vocabColumns + ` FROM vocabularies WHERE user_id = $1 AND word = ANY($2) AND deleted_at IS NULL`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(words))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanVocabRows(rows)
+}
+
 // itoa converts int to string for query building
 func itoa(i int) string {
 	return strconv.Itoa(i)
@@ -146,9 +603,12 @@ func itoa(i int) string {
 
 // Update updates a vocabulary entry
 func (r *repository) Update(ctx context.Context, vocab *Vocabulary) error {
-	query := `UPDATE vocabularies 
-			  SET word = $1, definition = $2, example = $3, translation = $4, status = $5, test_count = $6, passed_test_count = $7, failed_test_count = $8, updated_at = NOW() 
-			  WHERE id = $9`
+	query := `UPDATE vocabularies
+			  SET word = $1, definition = $2, example = $3, translation = $4, status = $5, test_count = $6, passed_test_count = $7, failed_test_count = $8,
+			      reverse_test_count = $9, reverse_passed_test_count = $10, reverse_failed_test_count = $11,
+			      ease_factor = $12, interval_days = $13, repetitions = $14, next_review_at = $15,
+			      part_of_speech = $16, pronunciation = $17, gender = $18, forms = $19, register = $20, usage_notes = $21, updated_at = NOW()
+			  WHERE id = $22`
 
 	_, err := r.db.ExecContext(ctx, query,
 		vocab.Word,
@@ -159,51 +619,37 @@ func (r *repository) Update(ctx context.Context, vocab *Vocabulary) error {
 		vocab.TestCount,
 		vocab.PassedTestCount,
 		vocab.FailedTestCount,
+		vocab.ReverseTestCount,
+		vocab.ReversePassedTestCount,
+		vocab.ReverseFailedTestCount,
+		vocab.EaseFactor,
+		vocab.IntervalDays,
+		vocab.Repetitions,
+		vocab.NextReviewAt,
+		vocab.PartOfSpeech,
+		vocab.Pronunciation,
+		vocab.Gender,
+		vocab.Forms,
+		vocab.Register,
+		vocab.UsageNotes,
 		vocab.ID,
 	)
 	return err
 }
 
-// Delete deletes a vocabulary entry
+// Delete moves a vocabulary entry to the trash
 func (r *repository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM vocabularies WHERE id = $1`
+	query := `UPDATE vocabularies SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-// FindRandomByUserIDAndStatus finds a random vocabulary by user ID and optional status filter
-func (r *repository) FindRandomByUserIDAndStatus(ctx context.Context, userID string, status string) (*Vocabulary, error) {
-	var query string
-	var args []any
-
-	if status == "" || status == "all" {
-		query = `SELECT id, user_id, word, definition, example, translation, status, test_count, passed_test_count, failed_test_count, created_at, updated_at 
-				 FROM vocabularies WHERE user_id = $1 
-				 ORDER BY RANDOM() LIMIT 1`
-		args = []any{userID}
-	} else {
-		query = `SELECT id, user_id, word, definition, example, translation, status, test_count, passed_test_count, failed_test_count, created_at, updated_at 
-				 FROM vocabularies WHERE user_id = $1 AND status = $2 
-				 ORDER BY RANDOM() LIMIT 1`
-		args = []any{userID, status}
-	}
+// FindTrashedByID finds a vocabulary in the trash by ID
+func (r *repository) FindTrashedByID(ctx context.Context, id string) (*Vocabulary, error) {
+	query := `SELECT ` + vocabColumns + ` FROM vocabularies WHERE id = $1 AND deleted_at IS NOT NULL`
 
 	var vocab Vocabulary
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(
-		&vocab.ID,
-		&vocab.UserID,
-		&vocab.Word,
-		&vocab.Definition,
-		&vocab.Example,
-		&vocab.Translation,
-		&vocab.Status,
-		&vocab.TestCount,
-		&vocab.PassedTestCount,
-		&vocab.FailedTestCount,
-		&vocab.CreatedAt,
-		&vocab.UpdatedAt,
-	)
-	if err != nil {
+	if err := scanVocab(r.db.QueryRowContext(ctx, query, id), &vocab); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -213,56 +659,140 @@ func (r *repository) FindRandomByUserIDAndStatus(ctx context.Context, userID str
 	return &vocab, nil
 }
 
-// FindRandomOptionsExcluding finds random vocabularies excluding a specific ID (for multiple choice options)
-func (r *repository) FindRandomOptionsExcluding(ctx context.Context, userID string, excludeID string, count int) ([]Vocabulary, error) {
-	query := `SELECT id, user_id, word, definition, example, translation, status, test_count, passed_test_count, failed_test_count, created_at, updated_at 
-			  FROM vocabularies WHERE user_id = $1 AND id != $2 AND translation IS NOT NULL AND translation != ''
-			  ORDER BY RANDOM() LIMIT $3`
+// FindTrashedByUserID finds the vocabularies in a user's trash with pagination, most recently deleted first
+func (r *repository) FindTrashedByUserID(ctx context.Context, userID string, page, pageSize int) ([]Vocabulary, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM vocabularies WHERE user_id = $1 AND deleted_at IS NOT NULL`
+	var total int64
+	if err := r.db.QueryRowContext(ctx, countQuery, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	query := `SELECT ` + vocabColumns + `
+			  FROM vocabularies WHERE user_id = $1 AND deleted_at IS NOT NULL
+			  ORDER BY deleted_at DESC, id DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	vocabularies, err := scanVocabRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return vocabularies, total, nil
+}
+
+// Restore moves a vocabulary entry out of the trash.
+// ErrWordExists is returned when an active vocabulary of the user has the same word.
+func (r *repository) Restore(ctx context.Context, id string) error {
+	query := `UPDATE vocabularies SET deleted_at = NULL WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return ErrWordExists
+	}
+	return err
+}
+
+// PurgeTrash permanently deletes vocabularies that have been in the trash longer than retention
+// and returns the number deleted
+func (r *repository) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	query := `DELETE FROM vocabularies WHERE deleted_at < NOW() - $1 * INTERVAL '1 second'`
+	result, err := r.db.ExecContext(ctx, query, int64(retention/time.Second))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// FindRandomOptionsExcluding finds random vocabularies excluding a specific ID (candidate multiple choice options).
+// Vocabularies with the given part of speech are picked first when it is set.
+func (r *repository) FindRandomOptionsExcluding(ctx context.Context, userID string, excludeID string, partOfSpeech PartOfSpeech, count int) ([]Vocabulary, error) {
+	query := `SELECT ` + vocabColumns + `
+			  FROM vocabularies WHERE user_id = $1 AND id != $2 AND deleted_at IS NULL AND jsonb_array_length(translation) > 0
+			  ORDER BY ($3 != '' AND part_of_speech = $3) DESC, RANDOM() LIMIT $4`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, excludeID, count)
+	rows, err := r.db.QueryContext(ctx, query, userID, excludeID, partOfSpeech, count)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var vocabularies []Vocabulary
-	for rows.Next() {
-		var vocab Vocabulary
-		if err := rows.Scan(
-			&vocab.ID,
-			&vocab.UserID,
-			&vocab.Word,
-			&vocab.Definition,
-			&vocab.Example,
-			&vocab.Translation,
-			&vocab.Status,
-			&vocab.TestCount,
-			&vocab.PassedTestCount,
-			&vocab.FailedTestCount,
-			&vocab.CreatedAt,
-			&vocab.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		vocabularies = append(vocabularies, vocab)
+	return scanVocabRows(rows)
+}
+
+// FindForTest finds up to limit distinct vocabularies matching the test filter.
+// Due words come first (most overdue first), the rest are picked randomly.
+func (r *repository) FindForTest(ctx context.Context, userID string, filter TestFilter, limit int) ([]Vocabulary, error) {
+	condition := "user_id = $1 AND deleted_at IS NULL"
+	args := []any{userID}
+	argIndex := 2
+
+	if filter.Status != "" && filter.Status != "all" {
+		condition += " AND status = $" + itoa(argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	if filter.DueOnly {
+		condition += " AND next_review_at <= NOW()"
+	}
+
+	condition, args, argIndex = appendLexicalCondition(condition, args, argIndex, filter.PartOfSpeech, "", "")
+	condition, args, argIndex = appendDeckCondition(condition, args, argIndex, filter.DeckID, filter.Tag)
+
+	// The prompt shown and the answer asked for the mode and direction must be present
+	switch {
+	case filter.Mode == ModeDefinition:
+		condition += " AND COALESCE(definition, '') != ''"
+	case filter.Mode == ModePronunciation:
+		condition += " AND pronunciation != ''"
+	case filter.Mode == ModeGender:
+		condition += " AND gender != ''"
+	case filter.Mode == ModePartOfSpeech:
+		condition += " AND part_of_speech != ''"
+	case filter.Mode == ModeForm:
+		condition += " AND forms != '{}'::JSONB"
+	case filter.Mode == ModeCloze:
+		condition += " AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(example) AS e(sentence) WHERE POSITION(LOWER(word) IN LOWER(e.sentence)) > 0)"
+	default:
+		condition += " AND jsonb_array_length(translation) > 0"
+	}
+
+	query := `SELECT ` + vocabColumns + `
+			  FROM vocabularies WHERE ` + condition + `
+			  ORDER BY (next_review_at <= NOW()) DESC, CASE WHEN next_review_at <= NOW() THEN next_review_at END ASC, RANDOM()
+			  LIMIT $` + itoa(argIndex)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
 
-	return vocabularies, nil
+	return scanVocabRows(rows)
 }
 
-// CountByUserIDAndStatus counts vocabularies by user ID and optional status filter
-func (r *repository) CountByUserIDAndStatus(ctx context.Context, userID string, status string) (int64, error) {
-	var query string
-	var args []any
+// FindDueByUserID finds vocabularies whose next review time has passed, most overdue first
+func (r *repository) FindDueByUserID(ctx context.Context, userID string, limit int) ([]Vocabulary, error) {
+	query := `SELECT ` + vocabColumns + `
+			  FROM vocabularies WHERE user_id = $1 AND deleted_at IS NULL AND next_review_at <= NOW()
+			  ORDER BY next_review_at ASC, id ASC LIMIT $2`
 
-	if status == "" || status == "all" {
-		query = `SELECT COUNT(*) FROM vocabularies WHERE user_id = $1`
-		args = []any{userID}
-	} else {
-		query = `SELECT COUNT(*) FROM vocabularies WHERE user_id = $1 AND status = $2`
-		args = []any{userID, status}
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
 	}
 
+	return scanVocabRows(rows)
+}
+
+// CountByUserID counts vocabularies by user ID matching the optional filter
+func (r *repository) CountByUserID(ctx context.Context, userID string, filter ListFilter) (int64, error) {
+	condition, args, _ := listCondition(userID, filter)
+	query := `SELECT COUNT(*) FROM vocabularies WHERE ` + condition
+
 	var count int64
 	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
 		return 0, err
@@ -270,3 +800,364 @@ func (r *repository) CountByUserIDAndStatus(ctx context.Context, userID string,
 
 	return count, nil
 }
+
+// CountDueByUserID counts vocabularies whose next review time has passed
+func (r *repository) CountDueByUserID(ctx context.Context, userID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM vocabularies WHERE user_id = $1 AND deleted_at IS NULL AND next_review_at <= NOW()`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SetTags replaces the decks of a vocabulary with the named ones, creating missing decks.
+// vocab.Tags is set to the stored deck names.
+func (r *repository) SetTags(ctx context.Context, vocab *Vocabulary, tags Tags) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM vocabulary_decks WHERE vocabulary_id = $1`, vocab.ID); err != nil {
+		return err
+	}
+
+	upsertDeck := `INSERT INTO decks (user_id, name, created_at, updated_at) VALUES ($1, $2, NOW(), NOW())
+				   ON CONFLICT (user_id, LOWER(name)) DO UPDATE SET name = decks.name
+				   RETURNING id, name`
+	link := `INSERT INTO vocabulary_decks (vocabulary_id, deck_id, created_at) VALUES ($1, $2, NOW())
+			 ON CONFLICT (vocabulary_id, deck_id) DO NOTHING`
+
+	stored := make(Tags, 0, len(tags))
+	for _, tag := range tags {
+		var deckID, name string
+		if err := r.db.QueryRowContext(ctx, upsertDeck, vocab.UserID, tag).Scan(&deckID, &name); err != nil {
+			return err
+		}
+		if _, err := r.db.ExecContext(ctx, link, vocab.ID, deckID); err != nil {
+			return err
+		}
+		stored = append(stored, name)
+	}
+
+	vocab.Tags = stored
+	return nil
+}
+
+// revisionColumns is the column list selected for a Revision, in scanRevision order
+const revisionColumns = `id, vocabulary_id, user_id, source, changes, reverted_to, created_at`
+
+// scanRevision scans a row selected with revisionColumns into a Revision
+func scanRevision(row rowScanner, revision *Revision) error {
+	var revertedTo sql.NullString
+	if err := row.Scan(
+		&revision.ID,
+		&revision.VocabularyID,
+		&revision.UserID,
+		&revision.Source,
+		&revision.Changes,
+		&revertedTo,
+		&revision.CreatedAt,
+	); err != nil {
+		return err
+	}
+	if revertedTo.Valid {
+		revision.RevertedTo = &revertedTo.String
+	}
+	return nil
+}
+
+// scanRevisionRows scans all rows selected with revisionColumns
+func scanRevisionRows(rows *sql.Rows) ([]Revision, error) {
+	defer rows.Close()
+
+	var revisions []Revision
+	for rows.Next() {
+		var revision Revision
+		if err := scanRevision(rows, &revision); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// CreateRevision records a change to a vocabulary. The wall clock time is used rather than the
+// transaction start so revisions made in one transaction keep their order.
+func (r *repository) CreateRevision(ctx context.Context, revision *Revision) error {
+	query := `INSERT INTO vocabulary_revisions (vocabulary_id, user_id, source, changes, reverted_to, created_at)
+			  VALUES ($1, $2, $3, $4, $5, clock_timestamp()) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		revision.VocabularyID,
+		revision.UserID,
+		revision.Source,
+		revision.Changes,
+		revision.RevertedTo,
+	).Scan(&revision.ID, &revision.CreatedAt)
+}
+
+// FindRevisionByID finds a revision by ID
+func (r *repository) FindRevisionByID(ctx context.Context, id string) (*Revision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM vocabulary_revisions WHERE id = $1`
+
+	var revision Revision
+	if err := scanRevision(r.db.QueryRowContext(ctx, query, id), &revision); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// FindRevisionsByVocabularyID finds the revision history of a vocabulary, newest first
+func (r *repository) FindRevisionsByVocabularyID(ctx context.Context, vocabID string, page, pageSize int) ([]Revision, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM vocabulary_revisions WHERE vocabulary_id = $1`
+	var total int64
+	if err := r.db.QueryRowContext(ctx, countQuery, vocabID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	query := `SELECT ` + revisionColumns + `
+			  FROM vocabulary_revisions WHERE vocabulary_id = $1
+			  ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, vocabID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	revisions, err := scanRevisionRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+// FindRevisionsAfter finds the revisions of the same vocabulary made after revision, newest first
+func (r *repository) FindRevisionsAfter(ctx context.Context, revision *Revision) ([]Revision, error) {
+	query := `SELECT ` + revisionColumns + `
+			  FROM vocabulary_revisions WHERE vocabulary_id = $1 AND (created_at, id) > ($2, $3)
+			  ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, revision.VocabularyID, revision.CreatedAt, revision.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRevisionRows(rows)
+}
+
+// CreateReview records a test answer in the review history
+func (r *repository) CreateReview(ctx context.Context, review *Review) error {
+	query := `INSERT INTO vocabulary_reviews (user_id, vocabulary_id, question_id, input, passed, grade, mode, direction, response_time_ms, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW()) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		review.UserID,
+		review.VocabularyID,
+		review.QuestionID,
+		review.Input,
+		review.Passed,
+		review.Grade,
+		review.Mode,
+		review.Direction,
+		review.ResponseTimeMs,
+	).Scan(&review.ID, &review.CreatedAt)
+}
+
+// reviewColumns is the column list selected for a Review, in scanReview order
+const reviewColumns = `id, user_id, vocabulary_id, question_id, input, passed, grade, mode, direction, response_time_ms, created_at`
+
+// scanReview scans a row selected with reviewColumns into a Review
+func scanReview(row rowScanner, review *Review) error {
+	var questionID sql.NullString
+	var responseTime sql.NullInt64
+	if err := row.Scan(
+		&review.ID,
+		&review.UserID,
+		&review.VocabularyID,
+		&questionID,
+		&review.Input,
+		&review.Passed,
+		&review.Grade,
+		&review.Mode,
+		&review.Direction,
+		&responseTime,
+		&review.CreatedAt,
+	); err != nil {
+		return err
+	}
+	if questionID.Valid {
+		review.QuestionID = &questionID.String
+	}
+	if responseTime.Valid {
+		review.ResponseTimeMs = &responseTime.Int64
+	}
+	return nil
+}
+
+// FindReviewByQuestionID finds the review recorded for the answer to an issued question
+func (r *repository) FindReviewByQuestionID(ctx context.Context, questionID string) (*Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM vocabulary_reviews WHERE question_id = $1`
+
+	var review Review
+	if err := scanReview(r.db.QueryRowContext(ctx, query, questionID), &review); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// FindReviewsByVocabularyID finds the review history of a vocabulary, newest first
+func (r *repository) FindReviewsByVocabularyID(ctx context.Context, vocabID string, page, pageSize int) ([]Review, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM vocabulary_reviews WHERE vocabulary_id = $1`
+	var total int64
+	if err := r.db.QueryRowContext(ctx, countQuery, vocabID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	query := `SELECT ` + reviewColumns + ` FROM vocabulary_reviews WHERE vocabulary_id = $1
+			  ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, vocabID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var reviews []Review
+	for rows.Next() {
+		var review Review
+		if err := scanReview(rows, &review); err != nil {
+			return nil, 0, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+// FindQuizSettings finds the quiz settings of a user, returning nil if none were saved
+func (r *repository) FindQuizSettings(ctx context.Context, userID string) (*QuizSettings, error) {
+	query := `SELECT user_id, answer_tolerance, updated_at FROM quiz_settings WHERE user_id = $1`
+
+	var settings QuizSettings
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&settings.UserID,
+		&settings.AnswerTolerance,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// UpsertQuizSettings creates or updates the quiz settings of a user
+func (r *repository) UpsertQuizSettings(ctx context.Context, settings *QuizSettings) error {
+	query := `INSERT INTO quiz_settings (user_id, answer_tolerance, created_at, updated_at)
+			  VALUES ($1, $2, NOW(), NOW())
+			  ON CONFLICT (user_id) DO UPDATE SET answer_tolerance = EXCLUDED.answer_tolerance, updated_at = NOW()
+			  RETURNING updated_at`
+
+	return r.db.QueryRowContext(ctx, query, settings.UserID, settings.AnswerTolerance).Scan(&settings.UpdatedAt)
+}
+
+// CreateQuestion stores an issued question and removes the user's expired unanswered ones.
+// Answered questions and questions of quiz session items are kept, so their graded answers can be looked up.
+func (r *repository) CreateQuestion(ctx context.Context, question *TestQuestion) error {
+	cleanup := `DELETE FROM test_questions q
+				WHERE q.user_id = $1 AND q.expires_at < NOW() AND q.answered_at IS NULL
+				AND NOT EXISTS (SELECT 1 FROM test_session_items i WHERE i.question_id = q.id)`
+	if _, err := r.db.ExecContext(ctx, cleanup, question.UserID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO test_questions (user_id, vocabulary_id, mode, direction, form_label, cloze, options, correct_option_id, practice, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW()) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		question.UserID,
+		question.VocabularyID,
+		question.Mode,
+		question.Direction,
+		question.FormLabel,
+		question.Cloze,
+		question.Options,
+		question.CorrectOptionID,
+		question.Practice,
+		question.ExpiresAt,
+	).Scan(&question.ID, &question.CreatedAt)
+}
+
+// FindQuestionByID finds an issued question by ID.
+// A malformed ID, such as a question token made up by a client, finds no question.
+func (r *repository) FindQuestionByID(ctx context.Context, id string) (*TestQuestion, error) {
+	if !utils.IsUUID(id) {
+		return nil, nil
+	}
+
+	query := `SELECT id, user_id, vocabulary_id, mode, direction, form_label, cloze, options, correct_option_id, practice, expires_at, answered_at, created_at
+			  FROM test_questions WHERE id = $1`
+
+	var question TestQuestion
+	var answeredAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&question.ID,
+		&question.UserID,
+		&question.VocabularyID,
+		&question.Mode,
+		&question.Direction,
+		&question.FormLabel,
+		&question.Cloze,
+		&question.Options,
+		&question.CorrectOptionID,
+		&question.Practice,
+		&question.ExpiresAt,
+		&answeredAt,
+		&question.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if answeredAt.Valid {
+		question.AnsweredAt = &answeredAt.Time
+	}
+
+	return &question, nil
+}
+
+// MarkQuestionAnswered marks a question as answered, returning false if it was already answered
+func (r *repository) MarkQuestionAnswered(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE test_questions SET answered_at = NOW() WHERE id = $1 AND answered_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,152 @@
+package vocab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// revisionFields are the content fields tracked by revisions, matching the JSON names of VocabContent
+var revisionFields = []string{
+	"word", "definition", "translation", "example", "tags",
+	"part_of_speech", "pronunciation", "gender", "forms", "register", "usage_notes",
+	"progress",
+}
+
+// VocabContent is the user-written content of a vocabulary tracked by revisions, along with its
+// learning progress. Progress changed by test answers is not recorded; changes to it by the user,
+// such as resetting it, are.
+type VocabContent struct {
+	Word        string       `json:"word"`
+	Definition  string       `json:"definition"`
+	Translation Translations `json:"translation"`
+	Example     Examples     `json:"example"`
+	Tags        Tags         `json:"tags"`
+
+	PartOfSpeech  PartOfSpeech `json:"part_of_speech"`
+	Pronunciation string       `json:"pronunciation"`
+	Gender        Gender       `json:"gender"`
+	Forms         Forms        `json:"forms"`
+	Register      Register     `json:"register"`
+	UsageNotes    string       `json:"usage_notes"`
+
+	Progress LearningProgress `json:"progress"`
+}
+
+// Content returns the revision-tracked content of the vocabulary
+func (v *Vocabulary) Content() VocabContent {
+	content := VocabContent{
+		Word:        v.Word,
+		Definition:  v.Definition,
+		Translation: v.Translation,
+		Example:     v.Example,
+		Tags:        append(Tags{}, v.Tags...),
+		Progress:    v.Progress(),
+
+		PartOfSpeech:  v.PartOfSpeech,
+		Pronunciation: v.Pronunciation,
+		Gender:        v.Gender,
+		Forms:         make(Forms, len(v.Forms)),
+		Register:      v.Register,
+		UsageNotes:    v.UsageNotes,
+	}
+	for label, form := range v.Forms {
+		content.Forms[label] = form
+	}
+	// Empty and missing lists are the same content
+	if content.Translation == nil {
+		content.Translation = Translations{}
+	}
+	if content.Example == nil {
+		content.Example = Examples{}
+	}
+	// Tags are listed in the order they are read back from the database
+	sort.SliceStable(content.Tags, func(i, j int) bool {
+		return strings.ToLower(content.Tags[i]) < strings.ToLower(content.Tags[j])
+	})
+	return content
+}
+
+// Lexical returns the lexical metadata of the content
+func (c VocabContent) Lexical() Lexical {
+	return Lexical{
+		PartOfSpeech:  c.PartOfSpeech,
+		Pronunciation: c.Pronunciation,
+		Gender:        c.Gender,
+		Forms:         c.Forms,
+		Register:      c.Register,
+		UsageNotes:    c.UsageNotes,
+	}
+}
+
+// fields returns the JSON value of each content field
+func (c VocabContent) fields() map[string]json.RawMessage {
+	data, _ := json.Marshal(c)
+	var fields map[string]json.RawMessage
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+// contentChanges returns the fields that differ between before and after.
+// A nil before records the initial content of a new vocabulary.
+func contentChanges(before *VocabContent, after VocabContent) RevisionChanges {
+	newFields := after.fields()
+	oldFields := map[string]json.RawMessage{}
+	if before != nil {
+		oldFields = before.fields()
+	}
+
+	changes := RevisionChanges{}
+	for _, field := range revisionFields {
+		old, ok := oldFields[field]
+		if ok && bytes.Equal(old, newFields[field]) {
+			continue
+		}
+		if !ok {
+			old = json.RawMessage("null")
+		}
+		changes[field] = FieldChange{Old: old, New: newFields[field]}
+	}
+	return changes
+}
+
+// undoRevisions returns content with the changes of revisions undone, in the given order
+func undoRevisions(content VocabContent, revisions []Revision) (VocabContent, error) {
+	fields := content.fields()
+	for _, revision := range revisions {
+		for field, change := range revision.Changes {
+			if _, ok := fields[field]; ok {
+				fields[field] = change.Old
+			}
+		}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return content, err
+	}
+	var undone VocabContent
+	if err := json.Unmarshal(data, &undone); err != nil {
+		return content, err
+	}
+	return undone, nil
+}
+
+// recordRevision stores the content changes of a vocabulary since before, if any.
+// A nil before records the creation of the vocabulary.
+func recordRevision(ctx context.Context, repo Repository, userID string, before *VocabContent, vocab *Vocabulary, source RevisionSource, revertedTo *string) error {
+	changes := contentChanges(before, vocab.Content())
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return repo.CreateRevision(ctx, &Revision{
+		VocabularyID: vocab.ID,
+		UserID:       userID,
+		Source:       source,
+		Changes:      changes,
+		RevertedTo:   revertedTo,
+	})
+}
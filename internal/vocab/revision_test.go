@@ -0,0 +1,140 @@
+package vocab
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRevertToCreation(t *testing.T) {
+	tests := []struct {
+		name    string
+		created Vocabulary
+		updates []func(v *Vocabulary)
+	}{
+		{
+			name:    "one update",
+			created: Vocabulary{Word: "house", Translation: Translations{"casa"}, Tags: Tags{"home"}},
+			updates: []func(v *Vocabulary){
+				func(v *Vocabulary) {
+					v.Definition = "a building to live in"
+					v.Translation = Translations{"casa", "hogar"}
+				},
+			},
+		},
+		{
+			name:    "several updates",
+			created: Vocabulary{Word: "Haus", Example: Examples{"Das Haus ist alt."}},
+			updates: []func(v *Vocabulary){
+				func(v *Vocabulary) { v.Word = "Häuschen" },
+				func(v *Vocabulary) { v.Gender = GenderNeuter },
+				func(v *Vocabulary) {
+					v.Word = "Haus"
+					v.Forms = Forms{"plural": "Häuser"}
+					v.Tags = Tags{"German", "buildings"}
+				},
+			},
+		},
+		{
+			name:    "fields starting empty",
+			created: Vocabulary{Word: "cat"},
+			updates: []func(v *Vocabulary){
+				func(v *Vocabulary) {
+					v.Translation = Translations{"gato"}
+					v.Example = Examples{"The cat sleeps."}
+					v.Forms = Forms{"plural": "cats"}
+				},
+				func(v *Vocabulary) { v.Translation = nil },
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vocab := tt.created
+			created := vocab.Content()
+
+			// Record the revisions, newest first like FindRevisionsAfter returns them
+			var later []Revision
+			for _, update := range tt.updates {
+				before := vocab.Content()
+				update(&vocab)
+				changes := contentChanges(&before, vocab.Content())
+				if len(changes) == 0 {
+					t.Fatal("update recorded no changes")
+				}
+				later = append([]Revision{{Changes: roundTrip(t, changes)}}, later...)
+			}
+
+			reverted, err := undoRevisions(vocab.Content(), later)
+			if err != nil {
+				t.Fatalf("undoRevisions() error = %v", err)
+			}
+			if changes := contentChanges(&created, reverted); len(changes) != 0 {
+				t.Errorf("reverted content differs from the creation in %v", changedFields(changes))
+			}
+		})
+	}
+}
+
+func TestContentChangesOfCreation(t *testing.T) {
+	vocab := Vocabulary{Word: "cat", Tags: Tags{"animals"}}
+
+	changes := contentChanges(nil, vocab.Content())
+	if len(changes) != len(revisionFields) {
+		t.Fatalf("creation changed %d fields, want all %d", len(changes), len(revisionFields))
+	}
+	for field, change := range changes {
+		if string(change.Old) != "null" {
+			t.Errorf("%s old = %s, want null", field, change.Old)
+		}
+	}
+
+	// Missing lists and forms are recorded as empty, not null
+	for field, want := range map[string]string{"word": `"cat"`, "translation": `[]`, "example": `[]`, "forms": `{}`, "tags": `["animals"]`} {
+		if got := string(changes[field].New); got != want {
+			t.Errorf("%s new = %s, want %s", field, got, want)
+		}
+	}
+
+	// Undoing the creation itself leaves empty content
+	undone, err := undoRevisions(vocab.Content(), []Revision{{Changes: roundTrip(t, changes)}})
+	if err != nil {
+		t.Fatalf("undoRevisions() error = %v", err)
+	}
+	if undone.Word != "" || len(undone.Translation) != 0 || len(undone.Example) != 0 || len(undone.Tags) != 0 || len(undone.Forms) != 0 {
+		t.Errorf("undoing the creation = %+v, want empty content", undone)
+	}
+}
+
+func TestContentChangesIgnoresEquivalentValues(t *testing.T) {
+	before := Vocabulary{Word: "cat", Tags: Tags{"b", "A"}}
+	after := Vocabulary{Word: "cat", Translation: Translations{}, Lexical: Lexical{Forms: Forms{}}, Tags: Tags{"A", "b"}}
+
+	beforeContent := before.Content()
+	if changes := contentChanges(&beforeContent, after.Content()); len(changes) != 0 {
+		t.Errorf("contentChanges() = %v, want none", changedFields(changes))
+	}
+}
+
+// roundTrip stores and reads back changes as the repository does
+func roundTrip(t *testing.T, changes RevisionChanges) RevisionChanges {
+	t.Helper()
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatalf("marshal changes: %v", err)
+	}
+	var stored RevisionChanges
+	if err := stored.Scan(data); err != nil {
+		t.Fatalf("scan changes: %v", err)
+	}
+	return stored
+}
+
+// changedFields lists the names of the changed fields
+func changedFields(changes RevisionChanges) []string {
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	return fields
+}
@@ -2,27 +2,100 @@ package vocab
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"io"
+	"log"
+	mathrand "math/rand/v2"
 	"strings"
+	"time"
+
+	"vocabulary-app-be/pkg/anki"
+	"vocabulary-app-be/pkg/grading"
 )
 
 var (
 	ErrVocabNotFound     = errors.New("vocabulary not found")
 	ErrUnauthorized      = errors.New("unauthorized access")
 	ErrNoVocabsAvailable = errors.New("no vocabularies available for testing")
+	ErrInvalidQuizMode   = errors.New("invalid quiz mode")
+	ErrInvalidDirection  = errors.New("invalid test direction")
+	ErrInvalidTolerance  = errors.New("invalid answer tolerance")
+	ErrQuestionNotFound  = errors.New("test question not found")
+	ErrQuestionExpired   = errors.New("test question has expired")
+	ErrQuestionAnswered  = errors.New("test question has already been answered")
+	ErrInvalidOption     = errors.New("invalid option")
+	ErrNotEnoughOptions  = errors.New("not enough vocabularies to build a full option set")
+
+	ErrInvalidImport            = errors.New("import contains invalid rows")
+	ErrInvalidImportOptions     = errors.New("invalid import options")
+	ErrInvalidColumnMapping     = errors.New("invalid import column mapping")
+	ErrInvalidDuplicateStrategy = errors.New("invalid duplicate strategy")
+	ErrRestoreNeedsOverwrite    = errors.New("restoring progress requires the overwrite strategy")
+	ErrEmptyImport              = errors.New("import file contains no rows")
+	ErrImportTooLarge           = errors.New("import file contains too many rows")
+	ErrUnsupportedImportFile    = errors.New("unsupported import file format or version")
+	ErrInvalidExportFormat      = errors.New("invalid export format")
+
+	ErrInvalidBulkAction = errors.New("invalid bulk action")
+	ErrInvalidStatus     = errors.New("invalid status")
+	ErrInvalidTag        = errors.New("invalid tag")
+	ErrDeckNotFound      = errors.New("deck not found")
+
+	ErrInvalidSort      = errors.New("invalid sort field")
+	ErrInvalidSortOrder = errors.New("invalid sort order")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+
+	ErrWordExists = errors.New("word already exists")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	ErrInvalidPartOfSpeech = errors.New("invalid part of speech")
+	ErrInvalidGender       = errors.New("invalid gender")
+	ErrInvalidRegister     = errors.New("invalid register")
+	ErrModeUnavailable     = errors.New("quiz mode not available for this vocabulary")
+	ErrInvalidForm         = errors.New("vocabulary has no form with this label")
 )
 
+// QuestionTTL is how long an issued question can be answered
+const QuestionTTL = 30 * time.Minute
+
 // Service handles business logic for vocabulary
 type Service interface {
 	Create(ctx context.Context, userID string, req *CreateVocabRequest) (*Vocabulary, error)
 	GetByID(ctx context.Context, userID, id string) (*Vocabulary, error)
-	GetByUserID(ctx context.Context, userID string, page, pageSize int, search, status string) (*VocabListResponse, error)
+	GetByUserID(ctx context.Context, userID string, page, pageSize int, filter ListFilter) (*VocabListResponse, error)
+	GetByUserIDAfter(ctx context.Context, userID, cursor string, pageSize int, filter ListFilter, withTotal bool) (*VocabListResponse, error)
 	Update(ctx context.Context, userID, id string, req *UpdateVocabRequest) (*Vocabulary, error)
 	Delete(ctx context.Context, userID, id string) error
-	GetRandomForTest(ctx context.Context, userID string, status string) (*TestVocabulary, error)
-	GetTestOptions(ctx context.Context, userID string, vocabID string) (*TestOptionsResponse, error)
-	GetVocabStats(ctx context.Context, userID string) (map[string]int64, error)
-	ValidateTestAnswer(ctx context.Context, userID, id string, input string) (*TestResultResponse, error)
+	Bulk(ctx context.Context, userID string, req *BulkRequest) (*BulkResponse, error)
+	AddToDeck(ctx context.Context, userID, deckID string, ids []string) (int64, error)
+	RemoveFromDeck(ctx context.Context, userID, deckID string, ids []string) (int64, error)
+	RenameDeck(ctx context.Context, userID, deckID, name string) error
+	DeleteDeck(ctx context.Context, userID, deckID string) error
+	GetTrash(ctx context.Context, userID string, page, pageSize int) (*TrashListResponse, error)
+	Restore(ctx context.Context, userID, id string) (*Vocabulary, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
+	Import(ctx context.Context, userID string, r io.Reader, req *ImportRequest) (*ImportResult, error)
+	ImportAnki(ctx context.Context, userID string, r io.ReaderAt, size int64, req *AnkiImportRequest) (*ImportResult, error)
+	Export(ctx context.Context, userID string, format ExportFormat, filter ListFilter, w io.Writer) error
+	ExportAnki(ctx context.Context, userID string, filter ListFilter, w io.Writer) error
+	GetRandomForTest(ctx context.Context, userID string, filter TestFilter) (*TestVocabulary, error)
+	IssueTestQuestion(ctx context.Context, userID, id string, mode QuizMode, direction Direction) (*TestVocabulary, error)
+	GetTestQuestion(ctx context.Context, userID, token string) (*TestVocabulary, error)
+	GetTestAnswer(ctx context.Context, userID, token string) (*TestResultResponse, error)
+	GetForTest(ctx context.Context, userID string, filter TestFilter, limit int) ([]Vocabulary, error)
+	GetDueForReview(ctx context.Context, userID string, limit int) (*DueReviewResponse, error)
+	IssueTestOptions(ctx context.Context, userID string, vocabID string, direction Direction, allowFallback bool) (*TestOptionsResponse, error)
+	GetVocabStats(ctx context.Context, userID string, filter ListFilter) (map[string]int64, error)
+	ValidateTestAnswer(ctx context.Context, userID, id string, req *TestResultRequest) (*TestResultResponse, error)
+	ValidateTestChoice(ctx context.Context, userID, id string, req *TestChoiceRequest) (*TestResultResponse, error)
+	GetReviewHistory(ctx context.Context, userID, id string, page, pageSize int) (*ReviewHistoryResponse, error)
+	GetRevisions(ctx context.Context, userID, id string, page, pageSize int) (*RevisionListResponse, error)
+	RevertToRevision(ctx context.Context, userID, id, revisionID string) (*Vocabulary, error)
+	GetQuizSettings(ctx context.Context, userID string) (*QuizSettings, error)
+	UpdateQuizSettings(ctx context.Context, userID string, req *UpdateQuizSettingsRequest) (*QuizSettings, error)
 }
 
 type service struct {
@@ -36,19 +109,37 @@ func NewService(repo Repository) Service {
 
 // Create creates a new vocabulary entry
 func (s *service) Create(ctx context.Context, userID string, req *CreateVocabRequest) (*Vocabulary, error) {
+	if err := req.Lexical.Validate(); err != nil {
+		return nil, err
+	}
+
 	vocab := &Vocabulary{
 		UserID:      userID,
 		Word:        req.Word,
 		Definition:  req.Definition,
 		Example:     req.Example,
-		Translation: req.Translation,
+		Translation: req.Translation.Clean(),
+		Tags:        Tags{},
+		Lexical:     req.Lexical.Clean(),
 		Status:      StatusLearning,
 		TestCount:   0,
 		PassedTestCount: 0,
 		FailedTestCount: 0,
+		EaseFactor:      DefaultEaseFactor,
 	}
 
-	if err := s.repo.Create(ctx, vocab); err != nil {
+	err := s.repo.WithTx(ctx, func(repo Repository) error {
+		if err := repo.Create(ctx, vocab); err != nil {
+			return err
+		}
+		if tags := req.Tags.Clean(); len(tags) > 0 {
+			if err := repo.SetTags(ctx, vocab, tags); err != nil {
+				return err
+			}
+		}
+		return recordRevision(ctx, repo, userID, nil, vocab, RevisionCreate, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -74,15 +165,18 @@ func (s *service) GetByID(ctx context.Context, userID, id string) (*Vocabulary,
 }
 
 // GetByUserID retrieves vocabularies by user ID with pagination
-func (s *service) GetByUserID(ctx context.Context, userID string, page, pageSize int, search, status string) (*VocabListResponse, error) {
+func (s *service) GetByUserID(ctx context.Context, userID string, page, pageSize int, filter ListFilter) (*VocabListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
 	if pageSize < 1 || pageSize > 100 {
 		pageSize = 10
 	}
+	if err := resolveSort(&filter); err != nil {
+		return nil, err
+	}
 
-	vocabularies, total, err := s.repo.FindByUserID(ctx, userID, page, pageSize, search, status)
+	vocabularies, total, err := s.repo.FindByUserID(ctx, userID, page, pageSize, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -94,43 +188,148 @@ func (s *service) GetByUserID(ctx context.Context, userID string, page, pageSize
 
 	return &VocabListResponse{
 		Data:       vocabularies,
-		Total:      total,
+		Total:      &total,
 		Page:       page,
 		PageSize:   pageSize,
 		TotalPages: totalPages,
-		Search:     search,
-		Status:     status,
+		Search:     filter.Search,
+		Status:     filter.Status,
+		DeckID:     filter.DeckID,
+		Tag:        filter.Tag,
+		Sort:       filter.Sort,
+		Order:      filter.Order,
 	}, nil
 }
 
-// Update updates a vocabulary entry
-func (s *service) Update(ctx context.Context, userID, id string, req *UpdateVocabRequest) (*Vocabulary, error) {
-	vocab, err := s.repo.FindByID(ctx, id)
+// GetByUserIDAfter retrieves the page of vocabularies following cursor; an empty cursor starts
+// at the beginning. Unlike page numbers, cursors do not shift when vocabularies are added.
+// A cursor only continues the sort it was created with. The matching total is only counted
+// when withTotal is set, so following cursors does not recount the collection on every page.
+func (s *service) GetByUserIDAfter(ctx context.Context, userID, cursor string, pageSize int, filter ListFilter, withTotal bool) (*VocabListResponse, error) {
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	var after *ListCursor
+	if cursor != "" {
+		var err error
+		if after, err = DecodeListCursor(cursor); err != nil {
+			return nil, err
+		}
+		if filter.Sort == "" && filter.Order == "" {
+			filter.Sort, filter.Order = after.Sort, after.Order
+		}
+	}
+	if err := resolveSort(&filter); err != nil {
+		return nil, err
+	}
+	if after != nil && (after.Sort != filter.Sort || after.Order != filter.Order) {
+		return nil, ErrInvalidCursor
+	}
+
+	vocabularies, next, err := s.repo.FindByUserIDAfter(ctx, userID, after, pageSize, filter)
 	if err != nil {
 		return nil, err
 	}
-	if vocab == nil {
-		return nil, ErrVocabNotFound
+
+	response := &VocabListResponse{
+		Data:     vocabularies,
+		PageSize: pageSize,
+		Search:   filter.Search,
+		Status:   filter.Status,
+		DeckID:   filter.DeckID,
+		Tag:      filter.Tag,
+		Sort:     filter.Sort,
+		Order:    filter.Order,
+	}
+	if next != nil {
+		response.NextCursor = next.Encode()
+	}
+	if withTotal {
+		total, err := s.repo.CountByUserID(ctx, userID, filter)
+		if err != nil {
+			return nil, err
+		}
+		response.Total = &total
 	}
 
-	// Check ownership
-	if vocab.UserID != userID {
-		return nil, ErrUnauthorized
+	return response, nil
+}
+
+// resolveSort validates the sort of filter and fills in defaults: relevance for searches,
+// newest first otherwise
+func resolveSort(filter *ListFilter) error {
+	if filter.Sort == "" {
+		filter.Sort = SortCreatedAt
+		if filter.Search != "" {
+			filter.Sort = SortRelevance
+		}
+	}
+	if !filter.Sort.IsValid() || (filter.Sort == SortRelevance && filter.Search == "") {
+		return ErrInvalidSort
 	}
 
-	// Update fields
-	if req.Word != "" {
-		vocab.Word = req.Word
+	if filter.Order == "" {
+		filter.Order = filter.Sort.DefaultOrder()
 	}
-	exampleValue, err := req.Example.Value()
-	if err == nil && exampleValue != "" {
-		vocab.Example = req.Example
+	if !filter.Order.IsValid() {
+		return ErrInvalidSortOrder
 	}
 
-	vocab.Definition = req.Definition
-	vocab.Translation = req.Translation
+	return nil
+}
+
+// Update updates a vocabulary entry
+func (s *service) Update(ctx context.Context, userID, id string, req *UpdateVocabRequest) (*Vocabulary, error) {
+	var vocab *Vocabulary
+	err := s.repo.WithTx(ctx, func(repo Repository) error {
+		// Lock the vocabulary so a test answer graded meanwhile is not overwritten
+		var err error
+		vocab, err = repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if vocab == nil {
+			return ErrVocabNotFound
+		}
+
+		// Check ownership
+		if vocab.UserID != userID {
+			return ErrUnauthorized
+		}
+
+		before := vocab.Content()
+
+		// Update fields
+		if req.Word != "" {
+			vocab.Word = req.Word
+		}
+		exampleValue, err := req.Example.Value()
+		if err == nil && exampleValue != "" {
+			vocab.Example = req.Example
+		}
+
+		vocab.Definition = req.Definition
+		vocab.Translation = req.Translation.Clean()
 
-	if err := s.repo.Update(ctx, vocab); err != nil {
+		lexical := req.ApplyLexical(vocab.Lexical)
+		if err := lexical.Validate(); err != nil {
+			return err
+		}
+		vocab.Lexical = lexical
+
+		if err := repo.Update(ctx, vocab); err != nil {
+			return err
+		}
+		// Tags are only replaced when present in the request
+		if req.Tags != nil {
+			if err := repo.SetTags(ctx, vocab, req.Tags.Clean()); err != nil {
+				return err
+			}
+		}
+		return recordRevision(ctx, repo, userID, &before, vocab, RevisionUpdate, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -155,89 +354,571 @@ func (s *service) Delete(ctx context.Context, userID, id string) error {
 	return s.repo.Delete(ctx, id)
 }
 
-// GetRandomForTest gets a random vocabulary for testing with optional status filter
-func (s *service) GetRandomForTest(ctx context.Context, userID string, status string) (*TestVocabulary, error) {
-	vocab, err := s.repo.FindRandomByUserIDAndStatus(ctx, userID, status)
+// GetTrash retrieves the vocabularies in a user's trash with pagination
+func (s *service) GetTrash(ctx context.Context, userID string, page, pageSize int) (*TrashListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	vocabularies, total, err := s.repo.FindTrashedByUserID(ctx, userID, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
-	if vocab == nil {
-		return nil, ErrNoVocabsAvailable
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
 	}
-	return vocab.ToTestVocabulary(), nil
+
+	return &TrashListResponse{
+		Data:       vocabularies,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
 }
 
-// GetTestOptions gets random vocabulary options for multiple-choice test (4 total: 1 correct + 3 wrong)
-func (s *service) GetTestOptions(ctx context.Context, userID string, vocabID string) (*TestOptionsResponse, error) {
-	// Get the correct answer (the vocabulary being tested)
-	correctVocab, err := s.repo.FindByID(ctx, vocabID)
+// Restore moves a vocabulary out of the trash. It fails with ErrWordExists when the
+// word was added again since it was deleted.
+func (s *service) Restore(ctx context.Context, userID, id string) (*Vocabulary, error) {
+	vocab, err := s.repo.FindTrashedByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if correctVocab == nil {
+	if vocab == nil {
 		return nil, ErrVocabNotFound
 	}
 
 	// Check ownership
-	if correctVocab.UserID != userID {
+	if vocab.UserID != userID {
 		return nil, ErrUnauthorized
 	}
 
-	// Get 3 random wrong options excluding the correct answer
-	wrongOptions, err := s.repo.FindRandomOptionsExcluding(ctx, userID, vocabID, 3)
+	existing, err := s.repo.FindByUserIDAndWords(ctx, userID, []string{vocab.Word})
 	if err != nil {
 		return nil, err
 	}
+	if len(existing) > 0 {
+		return nil, ErrWordExists
+	}
 
-	// Create TestOption array with correct answer + wrong answers
-	testOptions := make([]TestOption, 0, 4)
-	
-	// Add correct answer
-	testOptions = append(testOptions, TestOption{
-		ID:          correctVocab.ID,
-		Translation: correctVocab.Translation,
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	vocab.DeletedAt = nil
+	return vocab, nil
+}
+
+// PurgeTrash permanently deletes vocabularies that have been in the trash longer than retention
+func (s *service) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.repo.PurgeTrash(ctx, retention)
+}
+
+// RunTrashPurge purges the trash every interval until ctx is done
+func RunTrashPurge(ctx context.Context, service Service, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := service.PurgeTrash(ctx, retention)
+		if err != nil {
+			log.Printf("Failed to purge vocabulary trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d vocabularies from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Bulk applies an action to many vocabularies in a single transaction, locking them so
+// concurrent test answers are not overwritten. IDs that are missing or owned by another user
+// are reported as not found without affecting the others.
+func (s *service) Bulk(ctx context.Context, userID string, req *BulkRequest) (*BulkResponse, error) {
+	if !req.Action.IsValid() {
+		return nil, ErrInvalidBulkAction
+	}
+	if req.Action == BulkSetStatus && !req.Status.IsValid() {
+		return nil, ErrInvalidStatus
+	}
+	tag := strings.TrimSpace(req.Tag)
+	if (req.Action == BulkAddTag || req.Action == BulkRemoveTag) && tag == "" {
+		return nil, ErrInvalidTag
+	}
+
+	// Keep the first occurrence of each ID
+	ids := make([]string, 0, len(req.IDs))
+	seen := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	response := &BulkResponse{
+		Action:  req.Action,
+		Total:   len(ids),
+		Results: make([]BulkItemResult, 0, len(ids)),
+	}
+
+	err := s.repo.WithTx(ctx, func(repo Repository) error {
+		vocabularies, err := repo.FindByIDsForUpdate(ctx, userID, ids)
+		if err != nil {
+			return err
+		}
+		byID := make(map[string]*Vocabulary, len(vocabularies))
+		for i := range vocabularies {
+			byID[vocabularies[i].ID] = &vocabularies[i]
+		}
+
+		for _, id := range ids {
+			vocab, ok := byID[id]
+			if !ok {
+				response.Results = append(response.Results, BulkItemResult{ID: id, Error: ErrVocabNotFound.Error()})
+				continue
+			}
+
+			if err := applyBulkAction(ctx, repo, userID, vocab, req.Action, req.Status, tag); err != nil {
+				return err
+			}
+			response.Results = append(response.Results, BulkItemResult{ID: id, Success: true})
+		}
+
+		return nil
 	})
-	
-	// Add wrong answers
-	for _, opt := range wrongOptions {
-		testOptions = append(testOptions, TestOption{
-			ID:          opt.ID,
-			Translation: opt.Translation,
-		})
+	if err != nil {
+		return nil, err
 	}
 
-	return &TestOptionsResponse{
-		Options: testOptions,
-	}, nil
+	for _, result := range response.Results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	return response, nil
 }
 
-// GetVocabStats gets vocabulary statistics for the user
-func (s *service) GetVocabStats(ctx context.Context, userID string) (map[string]int64, error) {
-	stats := make(map[string]int64)
+// applyBulkAction applies a single bulk action to an owned vocabulary
+func applyBulkAction(ctx context.Context, repo Repository, userID string, vocab *Vocabulary, action BulkAction, status Status, tag string) error {
+	before := vocab.Content()
+
+	switch action {
+	case BulkDelete:
+		return repo.Delete(ctx, vocab.ID)
+	case BulkSetStatus:
+		vocab.Status = status
+		if err := repo.Update(ctx, vocab); err != nil {
+			return err
+		}
+		return recordRevision(ctx, repo, userID, &before, vocab, RevisionBulk, nil)
+	case BulkAddTag:
+		if err := repo.SetTags(ctx, vocab, append(vocab.Tags, tag).Clean()); err != nil {
+			return err
+		}
+		return recordRevision(ctx, repo, userID, &before, vocab, RevisionBulk, nil)
+	case BulkRemoveTag:
+		if err := repo.SetTags(ctx, vocab, vocab.Tags.Without(tag)); err != nil {
+			return err
+		}
+		return recordRevision(ctx, repo, userID, &before, vocab, RevisionBulk, nil)
+	case BulkResetCounters:
+		// Start learning again as if the vocabulary was new
+		vocab.ApplyProgress(LearningProgress{Status: StatusLearning, EaseFactor: DefaultEaseFactor, NextReviewAt: time.Now()})
+		if err := repo.Update(ctx, vocab); err != nil {
+			return err
+		}
+		return recordRevision(ctx, repo, userID, &before, vocab, RevisionBulk, nil)
+	}
+	return ErrInvalidBulkAction
+}
+
+// AddToDeck tags the user's vocabularies with the given IDs with a deck of the user, ignoring unknown IDs,
+// vocabularies in the trash and vocabularies already in the deck. It returns the number of vocabularies added.
+func (s *service) AddToDeck(ctx context.Context, userID, deckID string, ids []string) (int64, error) {
+	return s.changeDeckTags(ctx, userID, deckID, ids, func(tags Tags, name string) Tags {
+		if tags.Has(name) {
+			return tags
+		}
+		return append(tags, name)
+	})
+}
+
+// RemoveFromDeck removes the tag of a deck of the user from the user's vocabularies with the given IDs,
+// ignoring unknown IDs and vocabularies in the trash. It returns the number of vocabularies removed.
+func (s *service) RemoveFromDeck(ctx context.Context, userID, deckID string, ids []string) (int64, error) {
+	return s.changeDeckTags(ctx, userID, deckID, ids, Tags.Without)
+}
+
+// changeDeckTags replaces the tags of the given vocabularies with retag's, given the name of the deck,
+// and records a revision for each. It returns the number of vocabularies whose tags changed.
+func (s *service) changeDeckTags(ctx context.Context, userID, deckID string, ids []string, retag func(tags Tags, name string) Tags) (int64, error) {
+	var changed int64
+	err := s.repo.WithTx(ctx, func(repo Repository) error {
+		// Lock the deck so it is not renamed while tagging by name
+		name, err := repo.FindDeckNameForUpdate(ctx, userID, deckID)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return ErrDeckNotFound
+		}
+
+		vocabularies, err := repo.FindByIDsForUpdate(ctx, userID, ids)
+		if err != nil {
+			return err
+		}
+		for i := range vocabularies {
+			vocab := &vocabularies[i]
+			tags := retag(vocab.Tags, name)
+			if len(tags) == len(vocab.Tags) {
+				continue
+			}
+
+			before := vocab.Content()
+			if err := repo.SetTags(ctx, vocab, tags); err != nil {
+				return err
+			}
+			if err := recordRevision(ctx, repo, userID, &before, vocab, RevisionDeck, nil); err != nil {
+				return err
+			}
+			changed++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return changed, nil
+}
+
+// RenameDeck renames a deck of the user and records the renamed tag in a revision of each of its vocabularies.
+// A violation of the unique deck name index is returned as is.
+func (s *service) RenameDeck(ctx context.Context, userID, deckID, name string) error {
+	return s.repo.WithTx(ctx, func(repo Repository) error {
+		oldName, err := repo.FindDeckNameForUpdate(ctx, userID, deckID)
+		if err != nil {
+			return err
+		}
+		if oldName == "" {
+			return ErrDeckNotFound
+		}
+
+		vocabularies, err := repo.FindByDeckIDForUpdate(ctx, deckID)
+		if err != nil {
+			return err
+		}
+		if err := repo.RenameDeck(ctx, deckID, name); err != nil {
+			return err
+		}
+
+		for i := range vocabularies {
+			vocab := &vocabularies[i]
+			before := vocab.Content()
+			vocab.Tags = vocab.Tags.Renamed(oldName, name)
+			if err := recordRevision(ctx, repo, userID, &before, vocab, RevisionDeck, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteDeck deletes a deck of the user, keeping its vocabularies, and records the removed tag
+// in a revision of each of them
+func (s *service) DeleteDeck(ctx context.Context, userID, deckID string) error {
+	return s.repo.WithTx(ctx, func(repo Repository) error {
+		name, err := repo.FindDeckNameForUpdate(ctx, userID, deckID)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return ErrDeckNotFound
+		}
+
+		vocabularies, err := repo.FindByDeckIDForUpdate(ctx, deckID)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteDeck(ctx, deckID); err != nil {
+			return err
+		}
+
+		for i := range vocabularies {
+			vocab := &vocabularies[i]
+			before := vocab.Content()
+			vocab.Tags = vocab.Tags.Without(name)
+			if err := recordRevision(ctx, repo, userID, &before, vocab, RevisionDeck, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Import creates vocabularies from a CSV file or a JSON export in a single transaction.
+// JSON exports also restore the learning progress of created and overwritten vocabularies.
+// If any row is invalid nothing is imported and the result lists every row error with ErrInvalidImport.
+// Existing words are skipped, overwritten or merged depending on req.OnDuplicate.
+func (s *service) Import(ctx context.Context, userID string, r io.Reader, req *ImportRequest) (*ImportResult, error) {
+	if req.OnDuplicate == "" {
+		req.OnDuplicate = DuplicateSkip
+	}
+	if !req.OnDuplicate.IsValid() {
+		return nil, ErrInvalidDuplicateStrategy
+	}
+	if req.RestoreProgress && req.OnDuplicate != DuplicateOverwrite {
+		return nil, ErrRestoreNeedsOverwrite
+	}
+
+	format := req.Format
+	if format == "" {
+		format = ExportFormatCSV
+		if req.File != nil && strings.HasSuffix(strings.ToLower(req.File.Filename), ".json") {
+			format = ExportFormatJSON
+		}
+	}
 
-	total, err := s.repo.CountByUserIDAndStatus(ctx, userID, "")
+	var rows []ImportRow
+	var rowErrors []ImportRowError
+	var err error
+	switch format {
+	case ExportFormatCSV:
+		rows, rowErrors, err = parseImportCSV(r, req)
+	case ExportFormatJSON:
+		rows, rowErrors, err = parseImportJSON(r)
+	default:
+		return nil, ErrInvalidImportOptions
+	}
 	if err != nil {
 		return nil, err
 	}
-	stats["total"] = total
 
-	learning, err := s.repo.CountByUserIDAndStatus(ctx, userID, string(StatusLearning))
+	return s.importRows(ctx, userID, rows, rowErrors, req.OnDuplicate, req.RestoreProgress, req.DryRun)
+}
+
+// ImportAnki creates vocabularies from the notes of an Anki package (.apkg) in a single transaction.
+// The note's deck and tags become vocabulary tags.
+func (s *service) ImportAnki(ctx context.Context, userID string, r io.ReaderAt, size int64, req *AnkiImportRequest) (*ImportResult, error) {
+	if req.OnDuplicate == "" {
+		req.OnDuplicate = DuplicateSkip
+	}
+	if !req.OnDuplicate.IsValid() {
+		return nil, ErrInvalidDuplicateStrategy
+	}
+
+	notes, err := anki.Read(r, size)
 	if err != nil {
 		return nil, err
 	}
-	stats["learning"] = learning
 
-	memorized, err := s.repo.CountByUserIDAndStatus(ctx, userID, string(StatusMemorized))
+	rows, rowErrors, err := ankiNotesToRows(notes, req)
 	if err != nil {
 		return nil, err
 	}
-	stats["memorized"] = memorized
 
-	return stats, nil
+	return s.importRows(ctx, userID, rows, rowErrors, req.OnDuplicate, false, req.DryRun)
+}
+
+// Export streams the user's vocabularies matching filter to w as JSON or CSV
+func (s *service) Export(ctx context.Context, userID string, format ExportFormat, filter ListFilter, w io.Writer) error {
+	if !format.IsValid() {
+		return ErrInvalidExportFormat
+	}
+
+	writer := newExportWriter(w, format)
+	if err := s.repo.EachByUserID(ctx, userID, filter, writer.Write); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// ExportAnki writes the user's vocabularies matching filter as an Anki package (.apkg).
+// Learning progress is exported as card scheduling.
+func (s *service) ExportAnki(ctx context.Context, userID string, filter ListFilter, w io.Writer) error {
+	vocabularies, err := s.repo.FindAllByUserID(ctx, userID, filter)
+	if err != nil {
+		return err
+	}
+	if len(vocabularies) == 0 {
+		return ErrVocabNotFound
+	}
+
+	// Name the exported deck after the deck or tag the vocabularies were selected by
+	deckName := filter.Tag
+	if filter.DeckID != "" {
+		name, err := s.repo.FindDeckName(ctx, userID, filter.DeckID)
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			deckName = name
+		}
+	}
+
+	return anki.Write(w, vocabulariesToAnki(deckName, vocabularies))
+}
+
+// importRows stores validated import rows in a single transaction. Nothing is stored if
+// there are row errors or if dryRun is set; the result counts what would be done.
+// New vocabularies take the progress of their row; existing ones only with restoreProgress.
+func (s *service) importRows(ctx context.Context, userID string, rows []ImportRow, rowErrors []ImportRowError, onDuplicate DuplicateStrategy, restoreProgress, dryRun bool) (*ImportResult, error) {
+	result := &ImportResult{
+		Total:  len(rows) + countRows(rowErrors),
+		DryRun: dryRun,
+	}
+	if len(rowErrors) > 0 {
+		result.Errors = rowErrors
+		return result, ErrInvalidImport
+	}
+
+	err := s.repo.WithTx(ctx, func(repo Repository) error {
+		words := make([]string, 0, len(rows))
+		for i := range rows {
+			words = append(words, rows[i].Word)
+		}
+
+		existing, err := repo.FindByUserIDAndWords(ctx, userID, words)
+		if err != nil {
+			return err
+		}
+		byWord := make(map[string]*Vocabulary, len(existing))
+		for i := range existing {
+			byWord[existing[i].Word] = &existing[i]
+		}
+
+		for i := range rows {
+			row := &rows[i]
+
+			if vocab, ok := byWord[row.Word]; ok {
+				before := vocab.Content()
+				if !applyImportRow(vocab, row, onDuplicate) {
+					result.Skipped++
+					continue
+				}
+				if restoreProgress && row.Progress != nil {
+					vocab.ApplyProgress(*row.Progress)
+				}
+				result.Updated++
+				if dryRun {
+					continue
+				}
+				if err := repo.Update(ctx, vocab); err != nil {
+					return err
+				}
+				if err := repo.SetTags(ctx, vocab, vocab.Tags); err != nil {
+					return err
+				}
+				if err := recordRevision(ctx, repo, userID, &before, vocab, RevisionImport, nil); err != nil {
+					return err
+				}
+				continue
+			}
+
+			result.Created++
+			if dryRun {
+				continue
+			}
+			vocab := &Vocabulary{
+				UserID:      userID,
+				Word:        row.Word,
+				Definition:  row.Definition,
+				Example:     row.Example,
+				Translation: row.Translation,
+				Lexical:     row.Lexical,
+				Status:      StatusLearning,
+				EaseFactor:  DefaultEaseFactor,
+			}
+			if row.Progress != nil {
+				vocab.ApplyProgress(*row.Progress)
+			}
+			if err := repo.Create(ctx, vocab); err != nil {
+				return err
+			}
+			if len(row.Tags) > 0 {
+				if err := repo.SetTags(ctx, vocab, row.Tags); err != nil {
+					return err
+				}
+			}
+			if err := recordRevision(ctx, repo, userID, nil, vocab, RevisionImport, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// countRows counts the distinct rows with at least one error
+func countRows(rowErrors []ImportRowError) int {
+	rows := make(map[int]bool, len(rowErrors))
+	for _, rowError := range rowErrors {
+		rows[rowError.Row] = true
+	}
+	return len(rows)
+}
+
+// GetRandomForTest gets a vocabulary for testing with optional status filter.
+// Words due for review are returned first, otherwise a random word is picked.
+// No question is issued; questions about the vocabulary are issued by IssueTestQuestion
+// and, with their options, by IssueTestOptions.
+func (s *service) GetRandomForTest(ctx context.Context, userID string, filter TestFilter) (*TestVocabulary, error) {
+	direction, err := normalizeDirection(filter.Direction)
+	if err != nil {
+		return nil, err
+	}
+	filter.Direction = direction
+
+	mode, err := normalizeMode(filter.Mode)
+	if err != nil {
+		return nil, err
+	}
+	filter.Mode = mode
+
+	vocabularies, err := s.GetForTest(ctx, userID, filter, 1)
+	if err != nil {
+		return nil, err
+	}
+	return vocabularies[0].ToQuestion(mode, direction), nil
 }
 
-// ValidateTestAnswer validates the user's answer and updates test result
-func (s *service) ValidateTestAnswer(ctx context.Context, userID, id string, input string) (*TestResultResponse, error) {
+// IssueTestQuestion issues a free-text question about a vocabulary for the given mode and direction
+func (s *service) IssueTestQuestion(ctx context.Context, userID, id string, mode QuizMode, direction Direction) (*TestVocabulary, error) {
+	mode, err := normalizeMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	if mode == ModeMultipleChoice {
+		return nil, ErrInvalidQuizMode
+	}
+
+	direction, err = normalizeDirection(direction)
+	if err != nil {
+		return nil, err
+	}
+
 	vocab, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -251,38 +932,791 @@ func (s *service) ValidateTestAnswer(ctx context.Context, userID, id string, inp
 		return nil, ErrUnauthorized
 	}
 
-	// Validate answer - compare with translation (case-insensitive, trimmed)
-	correctAnswer := vocab.Translation
-	passed := strings.EqualFold(strings.TrimSpace(input), strings.TrimSpace(correctAnswer))
+	return s.issueQuestion(ctx, userID, vocab, mode, direction)
+}
 
-	// Update test counts
-	vocab.TestCount++
-	if passed {
-		vocab.PassedTestCount++
-	} else {
-		vocab.FailedTestCount++
+// GetTestQuestion gets the prompt of an issued free-text question that can still be answered
+func (s *service) GetTestQuestion(ctx context.Context, userID, token string) (*TestVocabulary, error) {
+	question, err := s.findOpenQuestion(ctx, userID, token)
+	if err != nil {
+		return nil, err
+	}
+	if question.Mode == ModeMultipleChoice {
+		return nil, ErrQuestionNotFound
 	}
 
-	// Auto-memorize if passed - failed >= 10
-	if vocab.PassedTestCount-vocab.FailedTestCount >= 10 && vocab.Status != StatusMemorized {
-		vocab.Status = StatusMemorized
-	} else if vocab.PassedTestCount-vocab.FailedTestCount < 10 && vocab.Status != StatusLearning {
-		vocab.Status = StatusLearning
+	vocab, err := s.repo.FindByID(ctx, question.VocabularyID)
+	if err != nil {
+		return nil, err
+	}
+	if vocab == nil {
+		return nil, ErrVocabNotFound
 	}
 
-	if err := s.repo.Update(ctx, vocab); err != nil {
+	return questionPrompt(vocab, question), nil
+}
+
+// GetTestAnswer gets the result recorded for an answered free-text question, so a caller
+// that failed to store the result of ValidateTestAnswer can recover it instead of grading again
+func (s *service) GetTestAnswer(ctx context.Context, userID, token string) (*TestResultResponse, error) {
+	question, err := s.repo.FindQuestionByID(ctx, token)
+	if err != nil {
 		return nil, err
 	}
+	if question == nil || question.UserID != userID || question.Mode == ModeMultipleChoice || question.AnsweredAt == nil {
+		return nil, ErrQuestionNotFound
+	}
 
-	response := &TestResultResponse{
-		Passed:     passed,
-		Vocabulary: *vocab.ToTestVocabulary(),
+	review, err := s.repo.FindReviewByQuestionID(ctx, question.ID)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, ErrQuestionNotFound
 	}
 
-	// Include correct answer if failed
-	if !passed {
-		response.CorrectAnswer = correctAnswer
+	vocab, err := s.repo.FindByID(ctx, question.VocabularyID)
+	if err != nil {
+		return nil, err
+	}
+	if vocab == nil {
+		return nil, ErrVocabNotFound
 	}
 
-	return response, nil
+	return &TestResultResponse{
+		Input:      review.Input,
+		Passed:     review.Passed,
+		Grade:      review.Grade,
+		Vocabulary: *vocab.ToTestVocabulary(question.Direction),
+	}, nil
+}
+
+// issueQuestion stores a free-text question about vocab and returns its prompt.
+// ErrModeUnavailable is returned when vocab lacks what the mode asks about.
+func (s *service) issueQuestion(ctx context.Context, userID string, vocab *Vocabulary, mode QuizMode, direction Direction) (*TestVocabulary, error) {
+	if !vocab.supportsMode(mode) {
+		return nil, ErrModeUnavailable
+	}
+
+	// Definition, cloze, pronunciation and metadata tests count as forward tests
+	if mode.IgnoresDirection() {
+		direction = DirectionForward
+	}
+
+	question := &TestQuestion{
+		UserID:       userID,
+		VocabularyID: vocab.ID,
+		Mode:         mode,
+		Direction:    direction,
+		ExpiresAt:    time.Now().Add(QuestionTTL),
+	}
+	switch mode {
+	case ModeForm:
+		question.FormLabel = randomFormLabel(vocab.Forms)
+	case ModeCloze:
+		question.Cloze = buildCloze(vocab.Word, vocab.Example)
+	}
+	if err := s.repo.CreateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+
+	return questionPrompt(vocab, question), nil
+}
+
+// questionPrompt builds the prompt of an issued free-text question
+func questionPrompt(vocab *Vocabulary, question *TestQuestion) *TestVocabulary {
+	test := vocab.ToQuestion(question.Mode, question.Direction)
+	test.Form = question.FormLabel
+	test.Cloze = question.Cloze
+	test.QuestionToken = question.ID
+	test.ExpiresAt = &question.ExpiresAt
+	return test
+}
+
+// findOpenQuestion finds an issued question of the user that is neither answered nor expired
+func (s *service) findOpenQuestion(ctx context.Context, userID, token string) (*TestQuestion, error) {
+	question, err := s.repo.FindQuestionByID(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if question == nil {
+		return nil, ErrQuestionNotFound
+	}
+
+	// Check ownership
+	if question.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	if question.AnsweredAt != nil {
+		return nil, ErrQuestionAnswered
+	}
+	if time.Now().After(question.ExpiresAt) {
+		return nil, ErrQuestionExpired
+	}
+
+	return question, nil
+}
+
+// GetForTest gets up to limit distinct vocabularies matching the filter for a test session
+func (s *service) GetForTest(ctx context.Context, userID string, filter TestFilter, limit int) ([]Vocabulary, error) {
+	vocabularies, err := s.repo.FindForTest(ctx, userID, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(vocabularies) == 0 {
+		return nil, ErrNoVocabsAvailable
+	}
+	return vocabularies, nil
+}
+
+// GetDueForReview gets the vocabularies due for review, most overdue first
+func (s *service) GetDueForReview(ctx context.Context, userID string, limit int) (*DueReviewResponse, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	vocabularies, err := s.repo.FindDueByUserID(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.repo.CountDueByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]TestVocabulary, 0, len(vocabularies))
+	for i := range vocabularies {
+		data = append(data, *vocabularies[i].ToTestVocabulary(DirectionForward))
+	}
+
+	remaining := total - int64(len(data))
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &DueReviewResponse{
+		Data:      data,
+		Total:     total,
+		Remaining: remaining,
+		Limit:     limit,
+	}, nil
+}
+
+// IssueTestOptions issues a multiple-choice question with vocabulary options (4 total: 1 correct + 3 wrong).
+// Wrong options are the user's vocabularies most similar to the answer. Small collections get
+// ErrNotEnoughOptions unless allowFallback is set; built-in distractors then fill the set and the
+// question is a practice question, since a client knowing the built-in list could rule them out.
+// Options are shuffled and identified by opaque IDs; the question is stored so the choice can be graded server-side.
+func (s *service) IssueTestOptions(ctx context.Context, userID string, vocabID string, direction Direction, allowFallback bool) (*TestOptionsResponse, error) {
+	direction, err := normalizeDirection(direction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the correct answer (the vocabulary being tested)
+	correctVocab, err := s.repo.FindByID(ctx, vocabID)
+	if err != nil {
+		return nil, err
+	}
+	if correctVocab == nil {
+		return nil, ErrVocabNotFound
+	}
+
+	// Check ownership
+	if correctVocab.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	// Forward questions need a translation as the correct option, like every distractor
+	correct := toTestOption(correctVocab, direction)
+	if optionText(&correct) == "" {
+		return nil, ErrModeUnavailable
+	}
+
+	// Score a pool of the user's other vocabularies and keep the most similar ones
+	candidates, err := s.repo.FindRandomOptionsExcluding(ctx, userID, vocabID, correctVocab.PartOfSpeech, distractorPoolSize)
+	if err != nil {
+		return nil, err
+	}
+	wrongOptions := selectDistractors(correctVocab, candidates, direction, OptionCount-1)
+
+	// Create TestOption array with correct answer + wrong answers
+	testOptions := make([]TestOption, 0, OptionCount)
+
+	// Add correct answer
+	testOptions = append(testOptions, toTestOption(correctVocab, direction))
+
+	// Add wrong answers
+	for _, wrong := range wrongOptions {
+		testOptions = append(testOptions, toTestOption(wrong, direction))
+	}
+
+	// Top up small collections with built-in distractors
+	practice := false
+	if len(testOptions) < OptionCount {
+		if !allowFallback {
+			return nil, ErrNotEnoughOptions
+		}
+		testOptions = fillFallbackDistractors(testOptions, direction, OptionCount)
+		practice = true
+	}
+
+	// Replace vocabulary IDs with opaque option IDs
+	for i := range testOptions {
+		optionID, err := newOptionID()
+		if err != nil {
+			return nil, err
+		}
+		testOptions[i].ID = optionID
+	}
+	correctOptionID := testOptions[0].ID
+
+	mathrand.Shuffle(len(testOptions), func(i, j int) {
+		testOptions[i], testOptions[j] = testOptions[j], testOptions[i]
+	})
+
+	question := &TestQuestion{
+		UserID:          userID,
+		VocabularyID:    correctVocab.ID,
+		Mode:            ModeMultipleChoice,
+		Direction:       direction,
+		Options:         testOptions,
+		CorrectOptionID: correctOptionID,
+		Practice:        practice,
+		ExpiresAt:       time.Now().Add(QuestionTTL),
+	}
+	if err := s.repo.CreateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+
+	return &TestOptionsResponse{
+		QuestionToken: question.ID,
+		Options:       testOptions,
+		Practice:      practice,
+		ExpiresAt:     question.ExpiresAt,
+	}, nil
+}
+
+// newOptionID generates a random opaque option ID
+func newOptionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// toTestOption builds a multiple-choice option showing the side asked for in the given direction
+func toTestOption(vocab *Vocabulary, direction Direction) TestOption {
+	if direction == DirectionReverse {
+		return TestOption{ID: vocab.ID, Word: vocab.Word}
+	}
+	return TestOption{ID: vocab.ID, Translation: vocab.Translation.Primary()}
+}
+
+// normalizeMode defaults an empty quiz mode to typing and validates it
+func normalizeMode(mode QuizMode) (QuizMode, error) {
+	if mode == "" {
+		return ModeTyping, nil
+	}
+	if !mode.IsValid() {
+		return "", ErrInvalidQuizMode
+	}
+	return mode, nil
+}
+
+// normalizeDirection defaults an empty direction to forward and validates it
+func normalizeDirection(direction Direction) (Direction, error) {
+	if direction == "" {
+		return DirectionForward, nil
+	}
+	if !direction.IsValid() {
+		return "", ErrInvalidDirection
+	}
+	return direction, nil
+}
+
+// GetVocabStats gets vocabulary statistics for the user, optionally limited to a deck
+func (s *service) GetVocabStats(ctx context.Context, userID string, filter ListFilter) (map[string]int64, error) {
+	stats := make(map[string]int64)
+
+	filter.Status = ""
+	total, err := s.repo.CountByUserID(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	stats["total"] = total
+
+	filter.Status = string(StatusLearning)
+	learning, err := s.repo.CountByUserID(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	stats["learning"] = learning
+
+	filter.Status = string(StatusMemorized)
+	memorized, err := s.repo.CountByUserID(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	stats["memorized"] = memorized
+
+	return stats, nil
+}
+
+// ValidateTestAnswer validates the user's answer to a free-text question and updates test result.
+// The answer is graded with the mode and direction the question was issued for; answers without
+// a question token are graded against the translations. Practice answers leave the vocabulary
+// and review history unchanged.
+func (s *service) ValidateTestAnswer(ctx context.Context, userID, id string, req *TestResultRequest) (*TestResultResponse, error) {
+	var question *TestQuestion
+	mode, direction, formLabel := ModeTyping, DirectionForward, ""
+	if req.QuestionToken != "" {
+		var err error
+		question, err = s.findOpenQuestion(ctx, userID, req.QuestionToken)
+		if err != nil {
+			return nil, err
+		}
+		if question.VocabularyID != id || question.Mode == ModeMultipleChoice {
+			return nil, ErrQuestionNotFound
+		}
+		mode, direction, formLabel = question.Mode, question.Direction, question.FormLabel
+	}
+
+	settings, err := s.GetQuizSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var vocab *Vocabulary
+	var correctAnswer string
+	var result grading.Result
+	err = s.repo.WithTx(ctx, func(repo Repository) error {
+		var questionID *string
+		if question != nil {
+			answered, err := repo.MarkQuestionAnswered(ctx, question.ID)
+			if err != nil {
+				return err
+			}
+			if !answered {
+				return ErrQuestionAnswered
+			}
+			questionID = &question.ID
+		}
+
+		// Lock the vocabulary so concurrent answers cannot overwrite each other's counters
+		var err error
+		vocab, err = repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if vocab == nil {
+			return ErrVocabNotFound
+		}
+
+		// Check ownership
+		if vocab.UserID != userID {
+			return ErrUnauthorized
+		}
+
+		correctAnswer, result, err = gradeTestAnswer(vocab, mode, direction, req.Input, formLabel, settings.AnswerTolerance)
+		if err != nil || req.Practice {
+			return err
+		}
+
+		applyTestResult(vocab, direction, result.Grade, time.Now())
+
+		// Save the result and record it in the review history
+		if err := repo.Update(ctx, vocab); err != nil {
+			return err
+		}
+		return repo.CreateReview(ctx, &Review{
+			UserID:         userID,
+			VocabularyID:   vocab.ID,
+			QuestionID:     questionID,
+			Input:          req.Input,
+			Passed:         result.Passed(),
+			Grade:          result.Grade,
+			Mode:           mode,
+			Direction:      direction,
+			ResponseTimeMs: req.ResponseTimeMs,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	passed := result.Passed()
+
+	response := &TestResultResponse{
+		Input:      req.Input,
+		Passed:     passed,
+		Grade:      result.Grade,
+		Practice:   req.Practice,
+		Vocabulary: *vocab.ToTestVocabulary(direction),
+	}
+
+	// Include correct answer and the highlighted difference unless fully correct
+	if result.Grade != grading.GradeCorrect {
+		response.CorrectAnswer = correctAnswer
+		response.Diff = result.Diff
+	}
+
+	return response, nil
+}
+
+// gradeTestAnswer grades input against the answer asked for by the quiz mode and direction:
+// any accepted translation, the word for reverse, definition, cloze and pronunciation tests,
+// or the metadata asked for. Gender and part of speech have a fixed set of answers and are graded strictly.
+func gradeTestAnswer(vocab *Vocabulary, mode QuizMode, direction Direction, input, formLabel string, tolerance grading.Tolerance) (string, grading.Result, error) {
+	switch {
+	case mode == ModeGender || mode == ModePartOfSpeech:
+		correctAnswer := string(vocab.Gender)
+		if mode == ModePartOfSpeech {
+			correctAnswer = string(vocab.PartOfSpeech)
+		}
+		if correctAnswer == "" {
+			return "", grading.Result{}, ErrModeUnavailable
+		}
+		return correctAnswer, grading.Evaluate(input, correctAnswer, grading.ToleranceStrict), nil
+	case mode == ModeForm:
+		form, ok := vocab.Forms.Get(formLabel)
+		if !ok {
+			return "", grading.Result{}, ErrInvalidForm
+		}
+		return form, grading.Evaluate(input, form, tolerance), nil
+	case direction == DirectionReverse || mode.AsksForWord():
+		return vocab.Word, grading.Evaluate(input, vocab.Word, tolerance), nil
+	default:
+		return vocab.Translation.String(), gradeAgainst(input, vocab.Translation, tolerance), nil
+	}
+}
+
+// ValidateTestChoice grades the option selected for an issued multiple-choice question.
+// Practice questions are only consumed; the vocabulary and review history are left unchanged.
+func (s *service) ValidateTestChoice(ctx context.Context, userID, id string, req *TestChoiceRequest) (*TestResultResponse, error) {
+	question, err := s.findOpenQuestion(ctx, userID, req.QuestionToken)
+	if err != nil {
+		return nil, err
+	}
+	if question.VocabularyID != id || question.Mode != ModeMultipleChoice {
+		return nil, ErrQuestionNotFound
+	}
+
+	var selected, correct *TestOption
+	for i := range question.Options {
+		if question.Options[i].ID == req.OptionID {
+			selected = &question.Options[i]
+		}
+		if question.Options[i].ID == question.CorrectOptionID {
+			correct = &question.Options[i]
+		}
+	}
+	if selected == nil || correct == nil {
+		return nil, ErrInvalidOption
+	}
+
+	grade := grading.GradeWrong
+	if selected.ID == correct.ID {
+		grade = grading.GradeCorrect
+	}
+	passed := grade == grading.GradeCorrect
+
+	// Consume the question, save the result and record it in the review history
+	var vocab *Vocabulary
+	err = s.repo.WithTx(ctx, func(repo Repository) error {
+		answered, err := repo.MarkQuestionAnswered(ctx, question.ID)
+		if err != nil {
+			return err
+		}
+		if !answered {
+			return ErrQuestionAnswered
+		}
+
+		// Lock the vocabulary so concurrent answers cannot overwrite each other's counters
+		vocab, err = repo.FindByIDForUpdate(ctx, question.VocabularyID)
+		if err != nil {
+			return err
+		}
+		if vocab == nil {
+			return ErrVocabNotFound
+		}
+		if question.Practice {
+			return nil
+		}
+
+		applyTestResult(vocab, question.Direction, grade, time.Now())
+
+		if err := repo.Update(ctx, vocab); err != nil {
+			return err
+		}
+		return repo.CreateReview(ctx, &Review{
+			UserID:         userID,
+			VocabularyID:   vocab.ID,
+			QuestionID:     &question.ID,
+			Input:          optionText(selected),
+			Passed:         passed,
+			Grade:          grade,
+			Mode:           ModeMultipleChoice,
+			Direction:      question.Direction,
+			ResponseTimeMs: req.ResponseTimeMs,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TestResultResponse{
+		Input:      optionText(selected),
+		Passed:     passed,
+		Grade:      grade,
+		Practice:   question.Practice,
+		Vocabulary: *vocab.ToTestVocabulary(question.Direction),
+	}
+
+	// Include correct answer if failed
+	if !passed {
+		response.CorrectAnswer = optionText(correct)
+		response.CorrectOptionID = correct.ID
+	}
+
+	return response, nil
+}
+
+// optionText returns the text shown for a multiple-choice option
+func optionText(option *TestOption) string {
+	if option.Word != "" {
+		return option.Word
+	}
+	return option.Translation
+}
+
+// applyTestResult updates the test counters, review schedule and status of a vocabulary after an answer
+func applyTestResult(vocab *Vocabulary, direction Direction, grade grading.Grade, now time.Time) {
+	passed := grade != grading.GradeWrong
+
+	if direction == DirectionReverse {
+		// Reverse recall is tracked separately and does not affect scheduling or status
+		vocab.ReverseTestCount++
+		if passed {
+			vocab.ReversePassedTestCount++
+		} else {
+			vocab.ReverseFailedTestCount++
+		}
+		return
+	}
+
+	// Update test counts
+	vocab.TestCount++
+	if passed {
+		vocab.PassedTestCount++
+	} else {
+		vocab.FailedTestCount++
+	}
+
+	// Schedule the next review
+	applySM2(vocab, qualityFromGrade(grade), now)
+
+	// Auto-memorize if passed - failed >= 10
+	if vocab.PassedTestCount-vocab.FailedTestCount >= 10 && vocab.Status != StatusMemorized {
+		vocab.Status = StatusMemorized
+	} else if vocab.PassedTestCount-vocab.FailedTestCount < 10 && vocab.Status != StatusLearning {
+		vocab.Status = StatusLearning
+	}
+}
+
+// gradeAgainst grades input against each accepted answer and returns the best result
+func gradeAgainst(input string, answers []string, tolerance grading.Tolerance) grading.Result {
+	best := grading.Result{Grade: grading.GradeWrong, Distance: -1}
+	for _, answer := range answers {
+		result := grading.Evaluate(input, answer, tolerance)
+		if result.Grade == grading.GradeCorrect {
+			return result
+		}
+		if best.Distance < 0 || gradeRank(result.Grade) > gradeRank(best.Grade) ||
+			(result.Grade == best.Grade && result.Distance < best.Distance) {
+			best = result
+		}
+	}
+	if best.Distance < 0 {
+		best.Distance = 0
+	}
+	return best
+}
+
+// gradeRank orders grades from worst to best
+func gradeRank(grade grading.Grade) int {
+	switch grade {
+	case grading.GradeCorrect:
+		return 2
+	case grading.GradeAlmost:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// GetReviewHistory retrieves the paginated review history of a vocabulary
+func (s *service) GetReviewHistory(ctx context.Context, userID, id string, page, pageSize int) (*ReviewHistoryResponse, error) {
+	if _, err := s.GetByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	reviews, total, err := s.repo.FindReviewsByVocabularyID(ctx, id, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	return &ReviewHistoryResponse{
+		Data:       reviews,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetRevisions retrieves the edit history of a vocabulary with pagination
+func (s *service) GetRevisions(ctx context.Context, userID, id string, page, pageSize int) (*RevisionListResponse, error) {
+	if _, err := s.GetByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	revisions, total, err := s.repo.FindRevisionsByVocabularyID(ctx, id, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	return &RevisionListResponse{
+		Data:       revisions,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// RevertToRevision restores the content of a vocabulary to what it was right after the given
+// revision by undoing every later revision. The revert is itself recorded as a revision.
+func (s *service) RevertToRevision(ctx context.Context, userID, id, revisionID string) (*Vocabulary, error) {
+	if _, err := s.GetByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.repo.FindRevisionByID(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil || revision.VocabularyID != id {
+		return nil, ErrRevisionNotFound
+	}
+
+	var vocab *Vocabulary
+	err = s.repo.WithTx(ctx, func(repo Repository) error {
+		// Lock the vocabulary so a concurrent update is neither lost nor left out of the undone revisions
+		vocab, err = repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if vocab == nil {
+			return ErrVocabNotFound
+		}
+
+		later, err := repo.FindRevisionsAfter(ctx, revision)
+		if err != nil {
+			return err
+		}
+
+		before := vocab.Content()
+		target, err := undoRevisions(before, later)
+		if err != nil {
+			return err
+		}
+
+		if target.Word != vocab.Word {
+			existing, err := repo.FindByUserIDAndWords(ctx, userID, []string{target.Word})
+			if err != nil {
+				return err
+			}
+			if len(existing) > 0 {
+				return ErrWordExists
+			}
+		}
+
+		vocab.Word = target.Word
+		vocab.Definition = target.Definition
+		vocab.Translation = target.Translation
+		vocab.Example = target.Example
+		vocab.Lexical = target.Lexical()
+		vocab.ApplyProgress(target.Progress)
+		if err := repo.Update(ctx, vocab); err != nil {
+			return err
+		}
+		if err := repo.SetTags(ctx, vocab, target.Tags); err != nil {
+			return err
+		}
+		return recordRevision(ctx, repo, userID, &before, vocab, RevisionRevert, &revision.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return vocab, nil
+}
+
+// GetQuizSettings retrieves the quiz settings of a user, falling back to defaults
+func (s *service) GetQuizSettings(ctx context.Context, userID string) (*QuizSettings, error) {
+	settings, err := s.repo.FindQuizSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &QuizSettings{
+			UserID:          userID,
+			AnswerTolerance: grading.DefaultTolerance,
+		}
+	}
+	return settings, nil
+}
+
+// UpdateQuizSettings updates the quiz settings of a user
+func (s *service) UpdateQuizSettings(ctx context.Context, userID string, req *UpdateQuizSettingsRequest) (*QuizSettings, error) {
+	if !req.AnswerTolerance.IsValid() {
+		return nil, ErrInvalidTolerance
+	}
+
+	settings := &QuizSettings{
+		UserID:          userID,
+		AnswerTolerance: req.AnswerTolerance,
+	}
+	if err := s.repo.UpsertQuizSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
 }
@@ -0,0 +1,135 @@
+package vocab
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const testUserID = "user-1"
+
+func TestValidateTestAnswerWithoutToken(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	s := NewService(repo)
+	vocab := repo.add(testUserID, "Haus", "house")
+
+	resp, err := s.ValidateTestAnswer(ctx, testUserID, vocab.ID, &TestResultRequest{Input: "house"})
+	if err != nil {
+		t.Fatalf("ValidateTestAnswer() error = %v", err)
+	}
+	if !resp.Passed || resp.Practice {
+		t.Errorf("ValidateTestAnswer() = %+v, want a passed answer that counts", resp)
+	}
+	if _, err := s.ValidateTestAnswer(ctx, testUserID, vocab.ID, &TestResultRequest{Input: "mouse house"}); err != nil {
+		t.Fatalf("ValidateTestAnswer() error = %v", err)
+	}
+
+	stored, _ := repo.FindByID(ctx, vocab.ID)
+	if stored.TestCount != 2 || stored.PassedTestCount != 1 || stored.FailedTestCount != 1 {
+		t.Errorf("counters = %d/%d/%d, want 2 tests with 1 passed and 1 failed",
+			stored.TestCount, stored.PassedTestCount, stored.FailedTestCount)
+	}
+	if stored.Repetitions != 0 || !stored.NextReviewAt.After(vocab.NextReviewAt) {
+		t.Errorf("schedule = %d repetitions due %v, want the failed answer scheduled", stored.Repetitions, stored.NextReviewAt)
+	}
+	reviews := repo.reviews.Filter(func(review *Review) bool { return review.VocabularyID == vocab.ID })
+	if len(reviews) != 2 || reviews[0].QuestionID != nil || reviews[0].Mode != ModeTyping {
+		t.Errorf("reviews = %+v, want 2 typing reviews without a question", reviews)
+	}
+}
+
+func TestValidateTestAnswerPractice(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	s := NewService(repo)
+	vocab := repo.add(testUserID, "Haus", "house")
+
+	resp, err := s.ValidateTestAnswer(ctx, testUserID, vocab.ID, &TestResultRequest{Input: "house", Practice: true})
+	if err != nil {
+		t.Fatalf("ValidateTestAnswer() error = %v", err)
+	}
+	if !resp.Passed || !resp.Practice {
+		t.Errorf("ValidateTestAnswer() = %+v, want a passed practice answer", resp)
+	}
+
+	stored, _ := repo.FindByID(ctx, vocab.ID)
+	if stored.TestCount != 0 || stored.Repetitions != 0 {
+		t.Errorf("practice answer changed the vocabulary to %+v", stored)
+	}
+	if reviews := repo.reviews.Filter(func(*Review) bool { return true }); len(reviews) != 0 {
+		t.Errorf("practice answer recorded reviews %+v", reviews)
+	}
+
+	if _, err := s.ValidateTestAnswer(ctx, "user-2", vocab.ID, &TestResultRequest{Input: "house"}); err != ErrUnauthorized {
+		t.Errorf("ValidateTestAnswer() by another user error = %v, want %v", err, ErrUnauthorized)
+	}
+}
+
+func TestBulkResetCounters(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	s := NewService(repo)
+	vocab := repo.add(testUserID, "Haus", "house")
+	learned := *vocab
+	learned.ApplyProgress(LearningProgress{
+		Status:          StatusMemorized,
+		TestCount:       12,
+		PassedTestCount: 11,
+		FailedTestCount: 1,
+		EaseFactor:      2.8,
+		IntervalDays:    40,
+		Repetitions:     6,
+		NextReviewAt:    time.Now().AddDate(0, 0, 40),
+	})
+	if err := repo.Update(ctx, &learned); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	resp, err := s.Bulk(ctx, testUserID, &BulkRequest{IDs: []string{vocab.ID}, Action: BulkResetCounters})
+	if err != nil {
+		t.Fatalf("Bulk() error = %v", err)
+	}
+	if resp.Succeeded != 1 {
+		t.Fatalf("Bulk() = %+v, want 1 vocabulary reset", resp)
+	}
+
+	stored, _ := repo.FindByID(ctx, vocab.ID)
+	if stored.Status != StatusLearning || stored.TestCount != 0 || stored.PassedTestCount != 0 {
+		t.Errorf("reset vocabulary is %s with %d tests, want learning without tests", stored.Status, stored.TestCount)
+	}
+	if stored.EaseFactor != DefaultEaseFactor || stored.IntervalDays != 0 || stored.Repetitions != 0 || stored.NextReviewAt.After(time.Now()) {
+		t.Errorf("reset schedule = %+v, want the defaults of a new vocabulary", stored.Progress())
+	}
+
+	revisions := repo.revisions.Filter(func(revision *Revision) bool { return revision.VocabularyID == vocab.ID })
+	if len(revisions) != 1 || revisions[0].Source != RevisionBulk {
+		t.Fatalf("revisions = %+v, want one bulk revision", revisions)
+	}
+	if _, ok := revisions[0].Changes["progress"]; !ok {
+		t.Errorf("revision changes %v, want the progress", changedFields(revisions[0].Changes))
+	}
+}
+
+func TestBulkSetStatusRecordsRevision(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	s := NewService(repo)
+	vocab := repo.add(testUserID, "Haus", "house")
+
+	if _, err := s.Bulk(ctx, testUserID, &BulkRequest{IDs: []string{vocab.ID}, Action: BulkSetStatus, Status: StatusMemorized}); err != nil {
+		t.Fatalf("Bulk() error = %v", err)
+	}
+
+	revisions := repo.revisions.Filter(func(revision *Revision) bool { return revision.VocabularyID == vocab.ID })
+	if len(revisions) != 1 {
+		t.Fatalf("got %d revisions, want 1", len(revisions))
+	}
+	undone, err := undoRevisions(vocab.Content(), revisions)
+	if err != nil {
+		t.Fatalf("undoRevisions() error = %v", err)
+	}
+	if undone.Progress.Status != StatusLearning {
+		t.Errorf("undoing the revision restores status %s, want learning", undone.Progress.Status)
+	}
+}
@@ -0,0 +1,74 @@
+package vocab
+
+import (
+	"math"
+	"time"
+
+	"vocabulary-app-be/pkg/grading"
+)
+
+// SM-2 scheduling parameters
+const (
+	DefaultEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+)
+
+// Quality grades an answer on the SM-2 0-5 scale
+type Quality int
+
+// A correct answer gets the top grade: with SM-2 only grade 5 raises the ease factor,
+// so any lower grade would make every word drift toward MinEaseFactor
+const (
+	QualityWrong   Quality = 1
+	QualityAlmost  Quality = 3
+	QualityCorrect Quality = 5
+)
+
+// qualityFromGrade maps an answer grade to an SM-2 quality grade
+func qualityFromGrade(grade grading.Grade) Quality {
+	switch grade {
+	case grading.GradeCorrect:
+		return QualityCorrect
+	case grading.GradeAlmost:
+		return QualityAlmost
+	default:
+		return QualityWrong
+	}
+}
+
+// applySM2 updates the vocabulary schedule using the SM-2 algorithm
+func applySM2(vocab *Vocabulary, quality Quality, now time.Time) {
+	if quality < 0 {
+		quality = 0
+	} else if quality > 5 {
+		quality = 5
+	}
+
+	if vocab.EaseFactor < MinEaseFactor {
+		vocab.EaseFactor = MinEaseFactor
+	}
+
+	if quality >= 3 {
+		switch vocab.Repetitions {
+		case 0:
+			vocab.IntervalDays = 1
+		case 1:
+			vocab.IntervalDays = 6
+		default:
+			vocab.IntervalDays = int(math.Round(float64(vocab.IntervalDays) * vocab.EaseFactor))
+		}
+		vocab.Repetitions++
+	} else {
+		// Lapse: start the repetition sequence again
+		vocab.Repetitions = 0
+		vocab.IntervalDays = 1
+	}
+
+	q := float64(5 - quality)
+	vocab.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if vocab.EaseFactor < MinEaseFactor {
+		vocab.EaseFactor = MinEaseFactor
+	}
+
+	vocab.NextReviewAt = now.AddDate(0, 0, vocab.IntervalDays)
+}
@@ -0,0 +1,141 @@
+package vocab
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"vocabulary-app-be/pkg/grading"
+)
+
+func TestApplySM2(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		vocab           Vocabulary
+		quality         Quality
+		wantInterval    int
+		wantRepetitions int
+		wantEaseFactor  float64
+	}{
+		{
+			name:            "first correct answer",
+			vocab:           Vocabulary{EaseFactor: DefaultEaseFactor},
+			quality:         QualityCorrect,
+			wantInterval:    1,
+			wantRepetitions: 1,
+			wantEaseFactor:  2.6,
+		},
+		{
+			name:            "second correct answer",
+			vocab:           Vocabulary{EaseFactor: DefaultEaseFactor, Repetitions: 1, IntervalDays: 1},
+			quality:         QualityCorrect,
+			wantInterval:    6,
+			wantRepetitions: 2,
+			wantEaseFactor:  2.6,
+		},
+		{
+			name:            "later correct answer multiplies the interval",
+			vocab:           Vocabulary{EaseFactor: DefaultEaseFactor, Repetitions: 2, IntervalDays: 6},
+			quality:         QualityCorrect,
+			wantInterval:    15,
+			wantRepetitions: 3,
+			wantEaseFactor:  2.6,
+		},
+		{
+			name:            "almost correct lowers the ease factor",
+			vocab:           Vocabulary{EaseFactor: DefaultEaseFactor, Repetitions: 2, IntervalDays: 6},
+			quality:         QualityAlmost,
+			wantInterval:    15,
+			wantRepetitions: 3,
+			wantEaseFactor:  2.36,
+		},
+		{
+			name:            "wrong answer restarts the sequence",
+			vocab:           Vocabulary{EaseFactor: DefaultEaseFactor, Repetitions: 4, IntervalDays: 40},
+			quality:         QualityWrong,
+			wantInterval:    1,
+			wantRepetitions: 0,
+			wantEaseFactor:  1.96,
+		},
+		{
+			name:            "ease factor does not drop below the minimum",
+			vocab:           Vocabulary{EaseFactor: 1.4, Repetitions: 3, IntervalDays: 10},
+			quality:         QualityWrong,
+			wantInterval:    1,
+			wantRepetitions: 0,
+			wantEaseFactor:  MinEaseFactor,
+		},
+		{
+			name:            "correct answers keep raising the ease factor",
+			vocab:           Vocabulary{EaseFactor: 2.8, Repetitions: 5, IntervalDays: 30},
+			quality:         QualityCorrect,
+			wantInterval:    84,
+			wantRepetitions: 6,
+			wantEaseFactor:  2.9,
+		},
+		{
+			name:            "ease factor below the minimum is clamped",
+			vocab:           Vocabulary{EaseFactor: 0.5},
+			quality:         QualityCorrect,
+			wantInterval:    1,
+			wantRepetitions: 1,
+			wantEaseFactor:  1.4,
+		},
+		{
+			name:            "quality above the scale is clamped",
+			vocab:           Vocabulary{EaseFactor: DefaultEaseFactor},
+			quality:         9,
+			wantInterval:    1,
+			wantRepetitions: 1,
+			wantEaseFactor:  2.6,
+		},
+		{
+			name:            "quality below the scale is clamped",
+			vocab:           Vocabulary{EaseFactor: DefaultEaseFactor, Repetitions: 2, IntervalDays: 6},
+			quality:         -2,
+			wantInterval:    1,
+			wantRepetitions: 0,
+			wantEaseFactor:  1.7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vocab := tt.vocab
+			applySM2(&vocab, tt.quality, now)
+
+			if vocab.IntervalDays != tt.wantInterval {
+				t.Errorf("interval = %d, want %d", vocab.IntervalDays, tt.wantInterval)
+			}
+			if vocab.Repetitions != tt.wantRepetitions {
+				t.Errorf("repetitions = %d, want %d", vocab.Repetitions, tt.wantRepetitions)
+			}
+			if math.Abs(vocab.EaseFactor-tt.wantEaseFactor) > 1e-9 {
+				t.Errorf("ease factor = %v, want %v", vocab.EaseFactor, tt.wantEaseFactor)
+			}
+			if want := now.AddDate(0, 0, tt.wantInterval); !vocab.NextReviewAt.Equal(want) {
+				t.Errorf("next review = %v, want %v", vocab.NextReviewAt, want)
+			}
+		})
+	}
+}
+
+func TestQualityFromGrade(t *testing.T) {
+	tests := []struct {
+		grade grading.Grade
+		want  Quality
+	}{
+		{grading.GradeCorrect, QualityCorrect},
+		{grading.GradeAlmost, QualityAlmost},
+		{grading.GradeWrong, QualityWrong},
+		{grading.Grade(""), QualityWrong},
+	}
+
+	for _, tt := range tests {
+		if got := qualityFromGrade(tt.grade); got != tt.want {
+			t.Errorf("qualityFromGrade(%q) = %d, want %d", tt.grade, got, tt.want)
+		}
+	}
+}
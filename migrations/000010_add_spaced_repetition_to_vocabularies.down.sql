@@ -0,0 +1,9 @@
+-- Drop the due review index
+DROP INDEX IF EXISTS idx_vocabularies_user_next_review;
+
+-- Remove SM-2 spaced repetition columns from vocabularies table
+ALTER TABLE vocabularies
+DROP COLUMN IF EXISTS ease_factor,
+DROP COLUMN IF EXISTS interval_days,
+DROP COLUMN IF EXISTS repetitions,
+DROP COLUMN IF EXISTS next_review_at;
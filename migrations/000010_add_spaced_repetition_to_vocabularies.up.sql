@@ -0,0 +1,9 @@
+-- Add SM-2 spaced repetition scheduling columns to vocabularies table
+ALTER TABLE vocabularies
+ADD COLUMN ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+ADD COLUMN interval_days INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN repetitions INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN next_review_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Create index for due review lookups
+CREATE INDEX IF NOT EXISTS idx_vocabularies_user_next_review ON vocabularies(user_id, next_review_at);
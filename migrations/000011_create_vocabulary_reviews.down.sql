@@ -0,0 +1,2 @@
+-- Drop vocabulary_reviews table
+DROP TABLE IF EXISTS vocabulary_reviews;
@@ -0,0 +1,15 @@
+-- Create vocabulary_reviews table to keep the history of every test answer
+CREATE TABLE IF NOT EXISTS vocabulary_reviews (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  vocabulary_id UUID NOT NULL REFERENCES vocabularies(id) ON DELETE CASCADE,
+  input TEXT NOT NULL,
+  passed BOOLEAN NOT NULL,
+  mode VARCHAR(50) NOT NULL DEFAULT 'typing',
+  response_time_ms INTEGER,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for history and analytics queries
+CREATE INDEX IF NOT EXISTS idx_vocabulary_reviews_vocabulary_id ON vocabulary_reviews(vocabulary_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_vocabulary_reviews_user_id ON vocabulary_reviews(user_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- Drop test_session_items table first (due to foreign key)
+DROP TABLE IF EXISTS test_session_items;
+
+-- Drop test_sessions table
+DROP TABLE IF EXISTS test_sessions;
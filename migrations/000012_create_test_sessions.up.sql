@@ -0,0 +1,30 @@
+-- Create test_sessions table for server-side quiz sessions
+CREATE TABLE IF NOT EXISTS test_sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR(50) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'abandoned')),
+  filter_status VARCHAR(50) NOT NULL DEFAULT 'all',
+  due_only BOOLEAN NOT NULL DEFAULT FALSE,
+  question_count INTEGER NOT NULL DEFAULT 0,
+  answered_count INTEGER NOT NULL DEFAULT 0,
+  correct_count INTEGER NOT NULL DEFAULT 0,
+  started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  completed_at TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create test_session_items table holding the fixed question set of a session
+CREATE TABLE IF NOT EXISTS test_session_items (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  session_id UUID NOT NULL REFERENCES test_sessions(id) ON DELETE CASCADE,
+  vocabulary_id UUID NOT NULL REFERENCES vocabularies(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  input TEXT,
+  passed BOOLEAN,
+  answered_at TIMESTAMP,
+  CONSTRAINT unique_session_position UNIQUE(session_id, position)
+);
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_test_sessions_user_id ON test_sessions(user_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_test_session_items_vocabulary_id ON test_session_items(vocabulary_id);
@@ -0,0 +1,13 @@
+-- Remove direction from quiz sessions
+ALTER TABLE test_sessions
+DROP COLUMN IF EXISTS direction;
+
+-- Remove direction from the review history
+ALTER TABLE vocabulary_reviews
+DROP COLUMN IF EXISTS direction;
+
+-- Remove reverse-direction test counters from vocabularies table
+ALTER TABLE vocabularies
+DROP COLUMN IF EXISTS reverse_test_count,
+DROP COLUMN IF EXISTS reverse_passed_test_count,
+DROP COLUMN IF EXISTS reverse_failed_test_count;
@@ -0,0 +1,13 @@
+-- Add reverse-direction (translation to word) test counters to vocabularies table
+ALTER TABLE vocabularies
+ADD COLUMN reverse_test_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN reverse_passed_test_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN reverse_failed_test_count INTEGER NOT NULL DEFAULT 0;
+
+-- Record the direction of every answer in the review history
+ALTER TABLE vocabulary_reviews
+ADD COLUMN direction VARCHAR(20) NOT NULL DEFAULT 'forward' CHECK (direction IN ('forward', 'reverse'));
+
+-- Record the direction of quiz sessions
+ALTER TABLE test_sessions
+ADD COLUMN direction VARCHAR(20) NOT NULL DEFAULT 'forward' CHECK (direction IN ('forward', 'reverse'));
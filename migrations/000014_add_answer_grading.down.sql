@@ -0,0 +1,6 @@
+-- Remove grade from the review history
+ALTER TABLE vocabulary_reviews
+DROP COLUMN IF EXISTS grade;
+
+-- Drop quiz_settings table
+DROP TABLE IF EXISTS quiz_settings;
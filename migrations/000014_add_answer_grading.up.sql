@@ -0,0 +1,14 @@
+-- Create quiz_settings table holding per-user quiz preferences
+CREATE TABLE IF NOT EXISTS quiz_settings (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  answer_tolerance VARCHAR(20) NOT NULL DEFAULT 'normal' CHECK (answer_tolerance IN ('strict', 'normal', 'lenient')),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Record the grade of every answer in the review history
+ALTER TABLE vocabulary_reviews
+ADD COLUMN grade VARCHAR(20) NOT NULL DEFAULT 'wrong' CHECK (grade IN ('correct', 'almost', 'wrong'));
+
+-- Backfill grades of existing reviews
+UPDATE vocabulary_reviews SET grade = 'correct' WHERE passed = TRUE;
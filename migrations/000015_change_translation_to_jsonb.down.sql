@@ -0,0 +1,8 @@
+-- Revert translation column back to VARCHAR, keeping only the first translation
+ALTER TABLE vocabularies
+ALTER COLUMN translation TYPE VARCHAR(255) USING CASE 
+  WHEN translation::text = '[]' THEN NULL
+  WHEN jsonb_array_length(translation) > 0 THEN translation->>0
+  ELSE NULL
+END,
+ALTER COLUMN translation DROP DEFAULT;
@@ -0,0 +1,8 @@
+-- Change translation column to support multiple accepted translations using JSON array
+ALTER TABLE vocabularies
+ALTER COLUMN translation TYPE JSONB USING CASE 
+  WHEN translation IS NULL THEN '[]'::JSONB
+  WHEN translation = '' THEN '[]'::JSONB
+  ELSE jsonb_build_array(translation)
+END,
+ALTER COLUMN translation SET DEFAULT '[]'::JSONB;
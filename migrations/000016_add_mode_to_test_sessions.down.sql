@@ -0,0 +1,3 @@
+-- Remove quiz mode from test_sessions table
+ALTER TABLE test_sessions
+DROP COLUMN IF EXISTS mode;
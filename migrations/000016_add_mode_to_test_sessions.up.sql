@@ -0,0 +1,3 @@
+-- Add quiz mode to test_sessions table
+ALTER TABLE test_sessions
+ADD COLUMN mode VARCHAR(50) NOT NULL DEFAULT 'typing' CHECK (mode IN ('typing', 'multiple_choice', 'definition', 'cloze'));
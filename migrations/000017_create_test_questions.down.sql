@@ -0,0 +1,2 @@
+-- Drop test_questions table
+DROP TABLE IF EXISTS test_questions;
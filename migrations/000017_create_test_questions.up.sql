@@ -0,0 +1,16 @@
+-- Create test_questions table holding issued multiple-choice questions
+CREATE TABLE IF NOT EXISTS test_questions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  vocabulary_id UUID NOT NULL REFERENCES vocabularies(id) ON DELETE CASCADE,
+  direction VARCHAR(20) NOT NULL DEFAULT 'forward' CHECK (direction IN ('forward', 'reverse')),
+  options JSONB NOT NULL DEFAULT '[]'::JSONB,
+  correct_option_id VARCHAR(64) NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  answered_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_test_questions_user_id ON test_questions(user_id, expires_at);
+CREATE INDEX IF NOT EXISTS idx_test_questions_vocabulary_id ON test_questions(vocabulary_id);
@@ -0,0 +1,3 @@
+-- Drop vocabulary_decks and decks tables
+DROP TABLE IF EXISTS vocabulary_decks;
+DROP TABLE IF EXISTS decks;
@@ -0,0 +1,23 @@
+-- Create decks table for user-defined decks and tags
+CREATE TABLE IF NOT EXISTS decks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Deck names are unique per user, ignoring case
+CREATE UNIQUE INDEX IF NOT EXISTS unique_user_deck_name ON decks(user_id, LOWER(name));
+
+-- Create vocabulary_decks table linking vocabularies to any number of decks
+CREATE TABLE IF NOT EXISTS vocabulary_decks (
+  vocabulary_id UUID NOT NULL REFERENCES vocabularies(id) ON DELETE CASCADE,
+  deck_id UUID NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (vocabulary_id, deck_id)
+);
+
+-- Create index for better query performance
+CREATE INDEX IF NOT EXISTS idx_vocabulary_decks_deck_id ON vocabulary_decks(deck_id);
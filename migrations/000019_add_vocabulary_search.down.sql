@@ -0,0 +1,7 @@
+-- Drop search indexes
+DROP INDEX IF EXISTS idx_vocabularies_translation_trgm;
+DROP INDEX IF EXISTS idx_vocabularies_word_trgm;
+DROP INDEX IF EXISTS idx_vocabularies_search_vector;
+
+-- Drop search_vector column
+ALTER TABLE vocabularies DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,15 @@
+-- Enable trigram matching for misspelling-tolerant search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Add search_vector column holding the words of each vocabulary, weighted by field
+ALTER TABLE vocabularies ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+  setweight(to_tsvector('simple', COALESCE(word, '')), 'A') ||
+  setweight(jsonb_to_tsvector('simple', COALESCE(translation, '[]'::JSONB), '["string"]'), 'B') ||
+  setweight(to_tsvector('simple', COALESCE(definition, '')), 'C') ||
+  setweight(jsonb_to_tsvector('simple', COALESCE(example, '[]'::JSONB), '["string"]'), 'D')
+) STORED;
+
+-- Create indexes for full-text and trigram search
+CREATE INDEX IF NOT EXISTS idx_vocabularies_search_vector ON vocabularies USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_vocabularies_word_trgm ON vocabularies USING GIN (word gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_vocabularies_translation_trgm ON vocabularies USING GIN ((translation::text) gin_trgm_ops);
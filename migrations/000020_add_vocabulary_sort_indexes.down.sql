@@ -0,0 +1,4 @@
+-- Drop vocabulary sort indexes
+DROP INDEX IF EXISTS idx_vocabularies_user_lower_word;
+DROP INDEX IF EXISTS idx_vocabularies_user_updated_at;
+DROP INDEX IF EXISTS idx_vocabularies_user_created_at;
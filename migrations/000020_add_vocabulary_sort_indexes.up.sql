@@ -0,0 +1,4 @@
+-- Create indexes for sorted and cursor-paginated vocabulary lists
+CREATE INDEX IF NOT EXISTS idx_vocabularies_user_created_at ON vocabularies(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_vocabularies_user_updated_at ON vocabularies(user_id, updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_vocabularies_user_lower_word ON vocabularies(user_id, LOWER(word), id);
@@ -0,0 +1,10 @@
+-- Permanently delete vocabularies in the trash so words are unique again
+DELETE FROM vocabularies WHERE deleted_at IS NOT NULL;
+
+-- Restore unique constraint on user_id and word
+DROP INDEX IF EXISTS idx_vocabularies_deleted_at;
+DROP INDEX IF EXISTS unique_user_word_active;
+ALTER TABLE vocabularies ADD CONSTRAINT unique_user_word UNIQUE(user_id, word);
+
+-- Drop deleted_at column
+ALTER TABLE vocabularies DROP COLUMN IF EXISTS deleted_at;
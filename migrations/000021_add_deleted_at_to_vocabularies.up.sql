@@ -0,0 +1,9 @@
+-- Add deleted_at column to move vocabularies to the trash instead of deleting them
+ALTER TABLE vocabularies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Words only need to be unique among vocabularies that are not in the trash
+ALTER TABLE vocabularies DROP CONSTRAINT IF EXISTS unique_user_word;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_user_word_active ON vocabularies(user_id, word) WHERE deleted_at IS NULL;
+
+-- Create index for listing and purging the trash
+CREATE INDEX IF NOT EXISTS idx_vocabularies_deleted_at ON vocabularies(deleted_at) WHERE deleted_at IS NOT NULL;
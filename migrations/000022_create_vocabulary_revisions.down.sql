@@ -0,0 +1,2 @@
+-- Drop vocabulary_revisions table
+DROP TABLE IF EXISTS vocabulary_revisions;
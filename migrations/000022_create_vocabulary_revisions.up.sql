@@ -0,0 +1,13 @@
+-- Create vocabulary_revisions table to keep the edit history of every vocabulary
+CREATE TABLE IF NOT EXISTS vocabulary_revisions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  vocabulary_id UUID NOT NULL REFERENCES vocabularies(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  source VARCHAR(20) NOT NULL,
+  changes JSONB NOT NULL,
+  reverted_to UUID REFERENCES vocabulary_revisions(id) ON DELETE SET NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for history queries
+CREATE INDEX IF NOT EXISTS idx_vocabulary_revisions_vocabulary_id ON vocabulary_revisions(vocabulary_id, created_at DESC);
@@ -0,0 +1,11 @@
+-- Drop part of speech index
+DROP INDEX IF EXISTS idx_vocabularies_user_part_of_speech;
+
+-- Drop lexical metadata columns
+ALTER TABLE vocabularies
+DROP COLUMN IF EXISTS usage_notes,
+DROP COLUMN IF EXISTS register,
+DROP COLUMN IF EXISTS forms,
+DROP COLUMN IF EXISTS gender,
+DROP COLUMN IF EXISTS pronunciation,
+DROP COLUMN IF EXISTS part_of_speech;
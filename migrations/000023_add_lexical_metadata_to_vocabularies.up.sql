@@ -0,0 +1,11 @@
+-- Add optional lexical metadata columns to vocabularies
+ALTER TABLE vocabularies
+ADD COLUMN IF NOT EXISTS part_of_speech VARCHAR(20) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS pronunciation VARCHAR(255) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS gender VARCHAR(20) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS forms JSONB NOT NULL DEFAULT '{}'::JSONB,
+ADD COLUMN IF NOT EXISTS register VARCHAR(20) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS usage_notes TEXT NOT NULL DEFAULT '';
+
+-- Create index for filtering by part of speech
+CREATE INDEX IF NOT EXISTS idx_vocabularies_user_part_of_speech ON vocabularies(user_id, part_of_speech);
@@ -0,0 +1,5 @@
+-- Drop the session reference from refresh tokens
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS refresh_tokens_family_id_fkey;
+
+-- Drop user_sessions table
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,27 @@
+-- Create user_sessions table holding one row per login. The session ID is the
+-- family ID of the refresh tokens issued for the login and the sid claim of its access tokens.
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  user_agent TEXT NOT NULL DEFAULT '',
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP NOT NULL,
+  revoked_at TIMESTAMP
+);
+
+-- Create a session for every existing refresh token family
+INSERT INTO user_sessions (id, user_id, created_at, last_seen_at, expires_at, revoked_at)
+SELECT family_id, user_id, MIN(created_at), MAX(created_at), MAX(expires_at),
+  CASE WHEN BOOL_AND(revoked_at IS NOT NULL) THEN MAX(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id, user_id;
+
+-- Refresh tokens belong to a session
+ALTER TABLE refresh_tokens ADD CONSTRAINT refresh_tokens_family_id_fkey
+  FOREIGN KEY (family_id) REFERENCES user_sessions(id) ON DELETE CASCADE;
+
+-- Create indexes for session lists and cleanup
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id, last_seen_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires_at ON user_sessions(expires_at);
@@ -0,0 +1,8 @@
+-- Revert to timestamps without time zone
+ALTER TABLE test_questions
+ALTER COLUMN answered_at TYPE TIMESTAMP,
+ALTER COLUMN expires_at TYPE TIMESTAMP;
+
+ALTER TABLE vocabularies
+ALTER COLUMN deleted_at TYPE TIMESTAMP,
+ALTER COLUMN next_review_at TYPE TIMESTAMP;
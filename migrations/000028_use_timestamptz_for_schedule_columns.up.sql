@@ -0,0 +1,9 @@
+-- Store timestamps written from the application with their time zone so they compare
+-- correctly with NOW(). Existing values are interpreted in the session time zone.
+ALTER TABLE vocabularies
+ALTER COLUMN next_review_at TYPE TIMESTAMPTZ,
+ALTER COLUMN deleted_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE test_questions
+ALTER COLUMN expires_at TYPE TIMESTAMPTZ,
+ALTER COLUMN answered_at TYPE TIMESTAMPTZ;
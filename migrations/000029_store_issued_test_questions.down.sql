@@ -0,0 +1,11 @@
+-- Drop question_id column from test_session_items
+ALTER TABLE test_session_items DROP COLUMN IF EXISTS question_id;
+
+-- Drop practice and cloze columns from test_questions
+ALTER TABLE test_questions DROP COLUMN IF EXISTS practice;
+ALTER TABLE test_questions DROP COLUMN IF EXISTS cloze;
+
+-- Free-text questions cannot be stored without a mode
+DELETE FROM test_questions WHERE mode != 'multiple_choice';
+ALTER TABLE test_questions ALTER COLUMN correct_option_id DROP DEFAULT;
+ALTER TABLE test_questions DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,12 @@
+-- Store free-text questions too, with the mode they were issued for, so answers are graded server-side
+ALTER TABLE test_questions ADD COLUMN IF NOT EXISTS mode VARCHAR(20) NOT NULL DEFAULT 'multiple_choice';
+ALTER TABLE test_questions ALTER COLUMN correct_option_id SET DEFAULT '';
+
+-- Store the sentence of cloze questions, so fetching an open question again shows the same one
+ALTER TABLE test_questions ADD COLUMN IF NOT EXISTS cloze TEXT NOT NULL DEFAULT '';
+
+-- Mark practice questions, whose answers do not count toward test results
+ALTER TABLE test_questions ADD COLUMN IF NOT EXISTS practice BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Link session items to the question issued for them
+ALTER TABLE test_session_items ADD COLUMN IF NOT EXISTS question_id UUID REFERENCES test_questions(id) ON DELETE SET NULL;
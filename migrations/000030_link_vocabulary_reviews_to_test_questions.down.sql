@@ -0,0 +1,3 @@
+-- Drop question_id column from vocabulary_reviews
+DROP INDEX IF EXISTS unique_vocabulary_reviews_question_id;
+ALTER TABLE vocabulary_reviews DROP COLUMN IF EXISTS question_id;
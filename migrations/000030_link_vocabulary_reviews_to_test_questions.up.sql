@@ -0,0 +1,3 @@
+-- Link reviews to the question they answer, so a graded answer can be looked up again
+ALTER TABLE vocabulary_reviews ADD COLUMN IF NOT EXISTS question_id UUID REFERENCES test_questions(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_vocabulary_reviews_question_id ON vocabulary_reviews(question_id);
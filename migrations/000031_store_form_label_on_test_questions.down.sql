@@ -0,0 +1,8 @@
+-- Sessions in the newer modes cannot be kept under the previous check
+DELETE FROM test_sessions WHERE mode NOT IN ('typing', 'multiple_choice', 'definition', 'cloze');
+ALTER TABLE test_sessions DROP CONSTRAINT IF EXISTS test_sessions_mode_check;
+ALTER TABLE test_sessions
+ADD CONSTRAINT test_sessions_mode_check CHECK (mode IN ('typing', 'multiple_choice', 'definition', 'cloze'));
+
+-- Drop form_label column from test_questions
+ALTER TABLE test_questions DROP COLUMN IF EXISTS form_label;
@@ -0,0 +1,7 @@
+-- Store the label of the form asked for in form questions, so it is not chosen by the client
+ALTER TABLE test_questions ADD COLUMN IF NOT EXISTS form_label VARCHAR(50) NOT NULL DEFAULT '';
+
+-- Allow quiz sessions in the pronunciation and lexical metadata modes
+ALTER TABLE test_sessions DROP CONSTRAINT IF EXISTS test_sessions_mode_check;
+ALTER TABLE test_sessions
+ADD CONSTRAINT test_sessions_mode_check CHECK (mode IN ('typing', 'multiple_choice', 'definition', 'cloze', 'pronunciation', 'gender', 'part_of_speech', 'form'));
@@ -0,0 +1,2 @@
+-- The spread due dates are kept; vocabularies never reviewed only become due later
+SELECT 1;
@@ -0,0 +1,12 @@
+-- Adding the SM-2 columns made every existing vocabulary due at once. Spread the first review of
+-- vocabularies that were never reviewed over the following days, 20 a day per user in the order
+-- they were added, so users with large collections are not faced with all of them on one day.
+UPDATE vocabularies v
+SET next_review_at = NOW() + (s.position / 20) * INTERVAL '1 day'
+FROM (
+  SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at, id) - 1 AS position
+  FROM vocabularies
+  WHERE repetitions = 0 AND next_review_at <= NOW()
+  AND NOT EXISTS (SELECT 1 FROM vocabulary_reviews r WHERE r.vocabulary_id = vocabularies.id)
+) s
+WHERE v.id = s.id;
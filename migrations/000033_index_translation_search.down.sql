@@ -0,0 +1,6 @@
+-- Drop translation text index and function
+DROP INDEX IF EXISTS idx_vocabularies_translation_text_trgm;
+DROP FUNCTION IF EXISTS vocabulary_translation_text(JSONB);
+
+-- Restore the trigram index over the JSON text of translations
+CREATE INDEX IF NOT EXISTS idx_vocabularies_translation_trgm ON vocabularies USING GIN ((translation::text) gin_trgm_ops);
@@ -0,0 +1,12 @@
+-- The trigram index over the JSON text of translations does not serve matches against
+-- each translation, and JSON quotes and separators would match in the JSON text
+DROP INDEX IF EXISTS idx_vocabularies_translation_trgm;
+
+-- Join the translations of a vocabulary into lines, so they can be searched in one indexed expression
+CREATE OR REPLACE FUNCTION vocabulary_translation_text(translation JSONB) RETURNS TEXT AS $$
+  SELECT COALESCE(string_agg(t.value, E'\n'), '')
+  FROM jsonb_array_elements_text(CASE WHEN jsonb_typeof(translation) = 'array' THEN translation ELSE '[]'::JSONB END) AS t(value)
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+-- Create index for substring and trigram search of translations
+CREATE INDEX IF NOT EXISTS idx_vocabularies_translation_text_trgm ON vocabularies USING GIN (vocabulary_translation_text(translation) gin_trgm_ops);
@@ -0,0 +1,160 @@
+// Package anki reads and writes Anki deck packages (.apkg).
+//
+// An .apkg file is a zip archive holding a SQLite collection ("collection.anki21" or
+// "collection.anki2") and a JSON media index. Only the legacy collection schema is
+// supported; packages exported with "Support older Anki versions" unchecked only contain
+// a compressed "collection.anki21b" and are rejected with ErrUnsupportedFormat.
+package anki
+
+import (
+	"archive/zip"
+	"database/sql"
+	"errors"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+var (
+	ErrInvalidPackage    = errors.New("invalid anki package")
+	ErrUnsupportedFormat = errors.New("unsupported anki collection format")
+	ErrCollectionTooBig  = errors.New("anki collection is too large")
+	ErrNoFields          = errors.New("anki note type needs at least one field")
+)
+
+// MaxCollectionSize is the maximum uncompressed size of a collection database in bytes
+const MaxCollectionSize = 200 << 20
+
+// fieldSeparator separates note field values in the notes table
+const fieldSeparator = "\x1f"
+
+// Note is a note read from a package
+type Note struct {
+	GUID   string
+	Model  string
+	Deck   string
+	Fields []Field
+	Tags   []string
+}
+
+// Field is a named note field
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Value returns the value of the first field with one of the given names (case-insensitive)
+func (n *Note) Value(names ...string) (string, bool) {
+	for _, name := range names {
+		for _, field := range n.Fields {
+			if strings.EqualFold(field.Name, name) {
+				return field.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// openCollection extracts the collection database of a package to a temporary file.
+// The returned cleanup function closes the database and removes the file.
+func openCollection(r io.ReaderAt, size int64) (*sql.DB, func(), error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, ErrInvalidPackage
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	// Newer packages hold the real collection in "collection.anki21b" and only a
+	// placeholder note asking to upgrade Anki in "collection.anki2"
+	entry, ok := files["collection.anki21"]
+	if !ok {
+		if _, compressed := files["collection.anki21b"]; compressed {
+			return nil, nil, ErrUnsupportedFormat
+		}
+		if entry, ok = files["collection.anki2"]; !ok {
+			return nil, nil, ErrInvalidPackage
+		}
+	}
+	if entry.UncompressedSize64 > MaxCollectionSize {
+		return nil, nil, ErrCollectionTooBig
+	}
+
+	tmp, err := os.CreateTemp("", "anki-*.sqlite")
+	if err != nil {
+		return nil, nil, err
+	}
+	remove := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+
+	src, err := entry.Open()
+	if err != nil {
+		remove()
+		return nil, nil, ErrInvalidPackage
+	}
+	written, err := io.Copy(tmp, io.LimitReader(src, MaxCollectionSize+1))
+	src.Close()
+	if err != nil {
+		remove()
+		return nil, nil, ErrInvalidPackage
+	}
+	if written > MaxCollectionSize {
+		remove()
+		return nil, nil, ErrCollectionTooBig
+	}
+	if err := tmp.Close(); err != nil {
+		remove()
+		return nil, nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+tmp.Name()+"?mode=ro")
+	if err != nil {
+		remove()
+		return nil, nil, err
+	}
+
+	return db, func() {
+		db.Close()
+		remove()
+	}, nil
+}
+
+// tagPattern matches HTML tags
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// breakPattern matches HTML elements that start a new line
+var breakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</?(div|p|li)[^>]*>`)
+
+// soundPattern matches Anki media references
+var soundPattern = regexp.MustCompile(`\[sound:[^\]]*\]`)
+
+// clozePattern matches Anki cloze deletions such as {{c1::word::hint}}
+var clozePattern = regexp.MustCompile(`\{\{c\d+::(.*?)(::[^}]*)?\}\}`)
+
+// StripHTML converts an Anki field value to plain text, keeping line breaks
+func StripHTML(s string) string {
+	s = breakPattern.ReplaceAllString(s, "\n")
+	s = tagPattern.ReplaceAllString(s, "")
+	s = soundPattern.ReplaceAllString(s, "")
+	s = clozePattern.ReplaceAllString(s, "$1")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
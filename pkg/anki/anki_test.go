@@ -0,0 +1,96 @@
+package anki
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	pkg := &Package{
+		DeckName:   "Spanish::Food",
+		ModelName:  "Vocabulary",
+		FieldNames: []string{"Word", "Translation", "Definition", "Example"},
+		Notes: []ExportNote{
+			{
+				GUID:   "note-1",
+				Fields: []string{"la manzana", "apple<br>apple tree fruit", "", "Como una manzana."},
+				Tags:   []string{"fruit", "daily life"},
+				Card:   Card{Due: time.Now().AddDate(0, 0, 3), IntervalDays: 6, EaseFactor: 2.5, Repetitions: 2},
+			},
+			{
+				GUID:   "note-2",
+				Fields: []string{"el pan", "bread", "baked food", ""},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, pkg); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	notes, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(notes) != len(pkg.Notes) {
+		t.Fatalf("Read() returned %d notes, want %d", len(notes), len(pkg.Notes))
+	}
+
+	for i, note := range notes {
+		want := pkg.Notes[i]
+		if note.GUID != want.GUID {
+			t.Errorf("note %d GUID = %q, want %q", i, note.GUID, want.GUID)
+		}
+		if note.Model != pkg.ModelName {
+			t.Errorf("note %d model = %q, want %q", i, note.Model, pkg.ModelName)
+		}
+		if note.Deck != pkg.DeckName {
+			t.Errorf("note %d deck = %q, want %q", i, note.Deck, pkg.DeckName)
+		}
+		for j, name := range pkg.FieldNames {
+			value, ok := note.Value(name)
+			if !ok || value != want.Fields[j] {
+				t.Errorf("note %d field %s = %q (found %v), want %q", i, name, value, ok, want.Fields[j])
+			}
+		}
+	}
+
+	// Spaces inside a tag become underscores
+	if want := []string{"fruit", "daily_life"}; !reflect.DeepEqual(notes[0].Tags, want) {
+		t.Errorf("note 0 tags = %v, want %v", notes[0].Tags, want)
+	}
+	if len(notes[1].Tags) != 0 {
+		t.Errorf("note 1 tags = %v, want none", notes[1].Tags)
+	}
+}
+
+func TestReadInvalidPackage(t *testing.T) {
+	data := []byte("not a zip archive")
+	if _, err := Read(bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrInvalidPackage) {
+		t.Errorf("Read() error = %v, want %v", err, ErrInvalidPackage)
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{"<b>bold</b> text", "bold text"},
+		{"first<br>second<br/>", "first\nsecond"},
+		{"{{c1::word::hint}} here", "word here"},
+		{"caf&eacute; &amp; tea", "café & tea"},
+	}
+
+	for _, tt := range tests {
+		if got := StripHTML(tt.input); got != tt.want {
+			t.Errorf("StripHTML(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package anki
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// model is the part of an Anki note type read from the col table
+type model struct {
+	Name   string `json:"name"`
+	Fields []struct {
+		Name string `json:"name"`
+		Ord  int    `json:"ord"`
+	} `json:"flds"`
+}
+
+// deck is the part of an Anki deck read from the col table
+type deck struct {
+	Name string `json:"name"`
+}
+
+// Read reads all notes of an .apkg package. Field values are returned as stored (HTML).
+func Read(r io.ReaderAt, size int64) ([]Note, error) {
+	db, cleanup, err := openCollection(r, size)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var modelsJSON, decksJSON string
+	if err := db.QueryRow(`SELECT models, decks FROM col LIMIT 1`).Scan(&modelsJSON, &decksJSON); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidPackage
+		}
+		return nil, ErrUnsupportedFormat
+	}
+
+	var models map[string]model
+	if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		return nil, ErrInvalidPackage
+	}
+	var decks map[string]deck
+	if err := json.Unmarshal([]byte(decksJSON), &decks); err != nil {
+		return nil, ErrInvalidPackage
+	}
+
+	// Field names of each model, in field order
+	fieldNames := make(map[string][]string, len(models))
+	for id, m := range models {
+		sort.Slice(m.Fields, func(i, j int) bool { return m.Fields[i].Ord < m.Fields[j].Ord })
+		names := make([]string, len(m.Fields))
+		for i, field := range m.Fields {
+			names[i] = field.Name
+		}
+		fieldNames[id] = names
+	}
+
+	rows, err := db.Query(`SELECT n.guid, n.mid, n.tags, n.flds,
+		COALESCE((SELECT c.did FROM cards c WHERE c.nid = n.id ORDER BY c.ord LIMIT 1), 0)
+		FROM notes n ORDER BY n.id`)
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		var guid, tags, fields string
+		var modelID, deckID int64
+		if err := rows.Scan(&guid, &modelID, &tags, &fields, &deckID); err != nil {
+			return nil, err
+		}
+
+		mid := strconv.FormatInt(modelID, 10)
+		names := fieldNames[mid]
+		values := strings.Split(fields, fieldSeparator)
+
+		note := Note{
+			GUID:  guid,
+			Model: models[mid].Name,
+			Deck:  decks[strconv.FormatInt(deckID, 10)].Name,
+			Tags:  strings.Fields(tags),
+		}
+		for i, value := range values {
+			name := "Field " + strconv.Itoa(i+1)
+			if i < len(names) {
+				name = names[i]
+			}
+			note.Fields = append(note.Fields, Field{Name: name, Value: value})
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
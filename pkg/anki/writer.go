@@ -0,0 +1,289 @@
+package anki
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Package describes an .apkg file to write: one deck with one note type
+type Package struct {
+	DeckName   string
+	ModelName  string
+	FieldNames []string
+	Notes      []ExportNote
+}
+
+// ExportNote is a note written to a package with a single card
+type ExportNote struct {
+	GUID   string
+	Fields []string
+	Tags   []string
+	Card   Card
+}
+
+// Card is the scheduling state of an exported card. Cards without repetitions are exported as new.
+type Card struct {
+	Due          time.Time
+	IntervalDays int
+	EaseFactor   float64
+	Repetitions  int
+	Lapses       int
+}
+
+// Card types and queues of the Anki scheduler
+const (
+	cardTypeNew    = 0
+	cardTypeReview = 2
+)
+
+// schema is the legacy (version 11) collection schema
+const schema = `
+CREATE TABLE col (id integer primary key, crt integer not null, mod integer not null, scm integer not null, ver integer not null,
+	dty integer not null, usn integer not null, ls integer not null, conf text not null, models text not null, decks text not null,
+	dconf text not null, tags text not null);
+CREATE TABLE notes (id integer primary key, guid text not null, mid integer not null, mod integer not null, usn integer not null,
+	tags text not null, flds text not null, sfld integer not null, csum integer not null, flags integer not null, data text not null);
+CREATE TABLE cards (id integer primary key, nid integer not null, did integer not null, ord integer not null, mod integer not null,
+	usn integer not null, type integer not null, queue integer not null, due integer not null, ivl integer not null,
+	factor integer not null, reps integer not null, lapses integer not null, left integer not null, odue integer not null,
+	odid integer not null, flags integer not null, data text not null);
+CREATE TABLE revlog (id integer primary key, cid integer not null, usn integer not null, ease integer not null, ivl integer not null,
+	lastIvl integer not null, factor integer not null, time integer not null, type integer not null);
+CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
+
+// Write writes pkg as an .apkg file to w
+func Write(w io.Writer, pkg *Package) error {
+	if len(pkg.FieldNames) == 0 {
+		return ErrNoFields
+	}
+
+	tmp, err := os.CreateTemp("", "anki-*.sqlite")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := writeCollection(tmp.Name(), pkg); err != nil {
+		return err
+	}
+
+	collection, err := os.Open(tmp.Name())
+	if err != nil {
+		return err
+	}
+	defer collection.Close()
+
+	archive := zip.NewWriter(w)
+	entry, err := archive.Create("collection.anki2")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(entry, collection); err != nil {
+		return err
+	}
+	media, err := archive.Create("media")
+	if err != nil {
+		return err
+	}
+	if _, err := media.Write([]byte("{}")); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// writeCollection creates the collection database of pkg at path
+func writeCollection(path string, pkg *Package) error {
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	// The collection is created today so card due days count from today
+	created := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	baseID := now.UnixMilli()
+	modelID := baseID
+	deckID := baseID + 1
+
+	models, decks, dconf, conf, err := collectionConfig(pkg, modelID, deckID, len(pkg.Notes), now)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		created.Unix(), now.UnixMilli(), now.UnixMilli(), conf, models, decks, dconf)
+	if err != nil {
+		return err
+	}
+
+	insertNote, err := tx.Prepare(`INSERT INTO notes VALUES (?, ?, ?, ?, 0, ?, ?, ?, ?, 0, '')`)
+	if err != nil {
+		return err
+	}
+	defer insertNote.Close()
+
+	insertCard, err := tx.Prepare(`INSERT INTO cards VALUES (?, ?, ?, 0, ?, 0, ?, ?, ?, ?, ?, ?, ?, 0, 0, 0, 0, '')`)
+	if err != nil {
+		return err
+	}
+	defer insertCard.Close()
+
+	for i, note := range pkg.Notes {
+		id := baseID + int64(i)
+		fields := make([]string, len(pkg.FieldNames))
+		copy(fields, note.Fields)
+		sortField := StripHTML(fields[0])
+
+		_, err := insertNote.Exec(id, note.GUID, modelID, now.Unix(), formatTags(note.Tags),
+			strings.Join(fields, fieldSeparator), sortField, checksum(sortField))
+		if err != nil {
+			return err
+		}
+
+		cardType, due, factor := cardTypeNew, int64(i+1), 0
+		if note.Card.Repetitions > 0 {
+			cardType = cardTypeReview
+			due = int64(math.Ceil(note.Card.Due.Sub(created).Hours() / 24))
+			factor = int(math.Round(note.Card.EaseFactor * 1000))
+		}
+
+		_, err = insertCard.Exec(id, id, deckID, now.Unix(), cardType, cardType, due,
+			note.Card.IntervalDays, factor, note.Card.Repetitions, note.Card.Lapses)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// collectionConfig builds the JSON configuration columns of the col table
+func collectionConfig(pkg *Package, modelID, deckID int64, noteCount int, now time.Time) (models, decks, dconf, conf string, err error) {
+	mid := strconv.FormatInt(modelID, 10)
+	did := strconv.FormatInt(deckID, 10)
+
+	fields := make([]map[string]any, len(pkg.FieldNames))
+	answer := "{{FrontSide}}\n\n<hr id=answer>\n\n"
+	for i, name := range pkg.FieldNames {
+		fields[i] = map[string]any{
+			"name": name, "ord": i, "sticky": false, "rtl": false,
+			"font": "Arial", "size": 20, "media": []any{},
+		}
+		if i > 0 {
+			answer += "{{#" + name + "}}<div>{{" + name + "}}</div>{{/" + name + "}}\n"
+		}
+	}
+
+	modelsJSON, err := json.Marshal(map[string]any{
+		mid: map[string]any{
+			"id": modelID, "name": pkg.ModelName, "type": 0, "mod": now.Unix(), "usn": 0,
+			"sortf": 0, "did": deckID, "flds": fields, "tags": []any{}, "vers": []any{},
+			"tmpls": []map[string]any{{
+				"name": "Card 1", "ord": 0, "did": nil, "bqfmt": "", "bafmt": "",
+				"qfmt": "{{" + pkg.FieldNames[0] + "}}", "afmt": answer,
+			}},
+			"css":       ".card { font-family: arial; font-size: 20px; text-align: center; color: black; background-color: white; }",
+			"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+			"latexPost": "\\end{document}",
+			"req":       []any{[]any{0, "all", []int{0}}},
+		},
+	})
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	deckJSON := func(id int64, name string) map[string]any {
+		return map[string]any{
+			"id": id, "name": name, "mod": now.Unix(), "usn": 0, "desc": "", "dyn": 0, "conf": 1,
+			"collapsed": false, "extendNew": 10, "extendRev": 50,
+			"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+		}
+	}
+	decksJSON, err := json.Marshal(map[string]any{
+		"1": deckJSON(1, "Default"),
+		did: deckJSON(deckID, pkg.DeckName),
+	})
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	dconfJSON, err := json.Marshal(map[string]any{
+		"1": map[string]any{
+			"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true, "timer": 0,
+			"replayq": true, "dyn": false,
+			"new": map[string]any{
+				"delays": []int{1, 10}, "ints": []int{1, 4, 7}, "initialFactor": 2500, "order": 1,
+				"perDay": 20, "bury": true, "separate": true,
+			},
+			"rev": map[string]any{
+				"perDay": 200, "ease4": 1.3, "fuzz": 0.05, "maxIvl": 36500, "bury": true, "minSpace": 1,
+			},
+			"lapse": map[string]any{
+				"delays": []int{10}, "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 0,
+			},
+		},
+	})
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	confJSON, err := json.Marshal(map[string]any{
+		"nextPos": noteCount + 1, "estTimes": true, "activeDecks": []int64{deckID}, "sortType": "noteFld",
+		"timeLim": 0, "sortBackwards": false, "addToCur": true, "curDeck": deckID, "newSpread": 0,
+		"dueCounts": true, "curModel": mid, "collapseTime": 1200,
+	})
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	return string(modelsJSON), string(decksJSON), string(dconfJSON), string(confJSON), nil
+}
+
+// formatTags joins tags the way Anki stores them; spaces inside a tag become underscores
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	formatted := make([]string, len(tags))
+	for i, tag := range tags {
+		formatted[i] = strings.Join(strings.Fields(tag), "_")
+	}
+	return " " + strings.Join(formatted, " ") + " "
+}
+
+// checksum returns the Anki checksum of a field: the first 8 hex digits of its SHA-1 as an integer
+func checksum(field string) int64 {
+	sum := sha1.Sum([]byte(field))
+	value, _ := strconv.ParseInt(hex.EncodeToString(sum[:4]), 16, 64)
+	return value
+}
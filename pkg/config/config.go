@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +14,31 @@ type Config struct {
 	JWTSecret   string
 	Environment string
 	CORSOrigin  string
+	// TrashRetentionDays is how long deleted vocabularies stay in the trash; 0 keeps them forever
+	TrashRetentionDays int
+	// AccessTokenTTLMinutes is the lifetime of JWT access tokens
+	AccessTokenTTLMinutes int
+	// RefreshTokenTTLDays is the lifetime of refresh tokens; every refresh issues a new one
+	RefreshTokenTTLDays int
+	// PasswordResetTTLMinutes is how long a password reset link stays valid
+	PasswordResetTTLMinutes int
+	// EmailVerificationMode restricts unverified accounts: "off", "read_only" or "blocked"
+	EmailVerificationMode string
+	// EmailVerificationTTLHours is how long an email verification link stays valid
+	EmailVerificationTTLHours int
+	// FrontendURL is the base URL of links sent in emails
+	FrontendURL string
+
+	// MailDriver selects how emails are delivered: "smtp", "file" or "log".
+	// It defaults to "log" in development only and must be "smtp" in production.
+	MailDriver string
+	MailFrom   string
+	// MailDir is the directory the file driver writes emails to
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
 }
 
 // Load loads configuration from environment variables
@@ -20,12 +46,38 @@ func Load() *Config {
 	// Load .env file (ignore error if file doesn't exist)
 	_ = godotenv.Load()
 
+	corsOrigin := getEnv("CORS_ORIGIN", "http://localhost:3000")
+	environment := getEnv("ENVIRONMENT", "development")
+
+	// Outside development emails must not silently end up in the log
+	mailDriver := getEnv("MAIL_DRIVER", "")
+	if mailDriver == "" && environment == "development" {
+		mailDriver = "log"
+	}
+
 	return &Config{
 		Port:        getEnv("PORT", "8080"),
 		DatabaseURL: getEnv("DATABASE_URL", "postgres://localhost:5432/vocabulary_db?sslmode=disable"),
 		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
-		Environment: getEnv("ENVIRONMENT", "development"),
-		CORSOrigin:  getEnv("CORS_ORIGIN", "http://localhost:3000"),
+		Environment: environment,
+		CORSOrigin:  corsOrigin,
+
+		TrashRetentionDays:    getEnvInt("TRASH_RETENTION_DAYS", 30),
+		AccessTokenTTLMinutes: getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15),
+		RefreshTokenTTLDays:   getEnvInt("REFRESH_TOKEN_TTL_DAYS", 30),
+
+		PasswordResetTTLMinutes:   getEnvInt("PASSWORD_RESET_TTL_MINUTES", 60),
+		EmailVerificationMode:     getEnv("EMAIL_VERIFICATION_MODE", "read_only"),
+		EmailVerificationTTLHours: getEnvInt("EMAIL_VERIFICATION_TTL_HOURS", 48),
+		FrontendURL:               getEnv("FRONTEND_URL", corsOrigin),
+
+		MailDriver:   mailDriver,
+		MailFrom:     getEnv("MAIL_FROM", "Vocabulary App <no-reply@localhost>"),
+		MailDir:      getEnv("MAIL_DIR", "tmp/mail"),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 	}
 }
 
@@ -36,3 +88,11 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt gets an integer environment variable with a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value >= 0 {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,265 @@
+package grading
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Grade represents how close an answer is to the expected answer
+type Grade string
+
+const (
+	GradeCorrect Grade = "correct"
+	GradeAlmost  Grade = "almost"
+	GradeWrong   Grade = "wrong"
+)
+
+// Tolerance controls how many mistakes are accepted as "almost" correct
+type Tolerance string
+
+const (
+	// ToleranceStrict only ignores case, punctuation, spacing and articles
+	ToleranceStrict Tolerance = "strict"
+	// ToleranceNormal also accepts missing accents and a typo in longer words
+	ToleranceNormal Tolerance = "normal"
+	// ToleranceLenient accepts missing accents and several typos
+	ToleranceLenient Tolerance = "lenient"
+)
+
+// DefaultTolerance is used when the user has not configured a tolerance
+const DefaultTolerance = ToleranceNormal
+
+// MaxInputLength is the longest input in runes that is compared with the expected answer.
+// Distance and Diff need time and memory proportional to the product of both lengths,
+// so longer inputs are graded wrong without computing them.
+const MaxInputLength = 500
+
+// IsValid checks if the tolerance is valid
+func (t Tolerance) IsValid() bool {
+	return t == ToleranceStrict || t == ToleranceNormal || t == ToleranceLenient
+}
+
+// DiffOp describes how a diff segment turns the input into the expected answer
+type DiffOp string
+
+const (
+	// DiffEqual text is the same in the input and the expected answer
+	DiffEqual DiffOp = "equal"
+	// DiffInsert text is missing from the input
+	DiffInsert DiffOp = "insert"
+	// DiffDelete text was typed but is not part of the expected answer
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffSegment is a run of characters sharing the same diff operation
+type DiffSegment struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// Result is the outcome of grading an answer
+type Result struct {
+	Grade    Grade
+	Distance int
+	Diff     []DiffSegment
+}
+
+// Passed reports whether the answer should count as a passed test
+func (r Result) Passed() bool {
+	return r.Grade != GradeWrong
+}
+
+// articles are leading words ignored when comparing answers
+var articles = map[string]bool{
+	"a": true, "an": true, "the": true, "to": true,
+	"l": true, "le": true, "la": true, "les": true, "un": true, "une": true,
+	"el": true, "los": true, "las": true, "una": true,
+	"der": true, "die": true, "das": true, "ein": true, "eine": true,
+}
+
+// Evaluate grades input against the expected answer using the given tolerance
+func Evaluate(input, expected string, tolerance Tolerance) Result {
+	if !tolerance.IsValid() {
+		tolerance = DefaultTolerance
+	}
+
+	in := Normalize(input)
+	want := Normalize(expected)
+	if length := len([]rune(in)); length > MaxInputLength {
+		return Result{Grade: GradeWrong, Distance: length}
+	}
+	if want == "" {
+		return Result{Grade: GradeWrong, Distance: len([]rune(in))}
+	}
+	if in == want {
+		return Result{Grade: GradeCorrect}
+	}
+
+	diff := Diff(in, want)
+	foldedIn := FoldDiacritics(in)
+	foldedWant := FoldDiacritics(want)
+	distance := Distance(foldedIn, foldedWant)
+
+	if tolerance == ToleranceStrict {
+		return Result{Grade: GradeWrong, Distance: Distance(in, want), Diff: diff}
+	}
+
+	// Only accents differ
+	if foldedIn == foldedWant {
+		return Result{Grade: GradeAlmost, Distance: Distance(in, want), Diff: diff}
+	}
+
+	if distance <= maxEdits(tolerance, len([]rune(foldedWant))) {
+		return Result{Grade: GradeAlmost, Distance: distance, Diff: diff}
+	}
+
+	return Result{Grade: GradeWrong, Distance: distance, Diff: diff}
+}
+
+// maxEdits returns the number of typos accepted for an answer of the given length
+func maxEdits(tolerance Tolerance, length int) int {
+	switch tolerance {
+	case ToleranceLenient:
+		switch {
+		case length <= 2:
+			return 0
+		case length <= 5:
+			return 1
+		case length <= 10:
+			return 2
+		default:
+			return 3
+		}
+	case ToleranceNormal:
+		switch {
+		case length <= 3:
+			return 0
+		case length <= 7:
+			return 1
+		default:
+			return 2
+		}
+	default:
+		return 0
+	}
+}
+
+// Normalize lowercases s, removes punctuation, collapses whitespace and drops a leading article
+func Normalize(s string) string {
+	s = norm.NFC.String(strings.ToLower(s))
+
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	fields := strings.Fields(b.String())
+	if len(fields) > 1 && articles[fields[0]] {
+		fields = fields[1:]
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// FoldDiacritics removes accents and other combining marks from s
+func FoldDiacritics(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return folded
+}
+
+// Distance returns the optimal string alignment distance between a and b,
+// counting insertions, deletions, substitutions and adjacent transpositions
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	n, m := len(ra), len(rb)
+
+	d := make([][]int, n+1)
+	for i := range d {
+		d[i] = make([]int, m+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[n][m]
+}
+
+// Diff returns the character-level difference turning input into expected
+func Diff(input, expected string) []DiffSegment {
+	a, b := []rune(input), []rune(expected)
+	n, m := len(a), len(b)
+
+	// Longest common subsequence table, filled from the end
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var segments []DiffSegment
+	add := func(op DiffOp, r rune) {
+		if last := len(segments) - 1; last >= 0 && segments[last].Op == op {
+			segments[last].Text += string(r)
+			return
+		}
+		segments = append(segments, DiffSegment{Op: op, Text: string(r)})
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			add(DiffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(DiffDelete, a[i])
+			i++
+		default:
+			add(DiffInsert, b[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		add(DiffDelete, a[i])
+	}
+	for ; j < m; j++ {
+		add(DiffInsert, b[j])
+	}
+
+	return segments
+}
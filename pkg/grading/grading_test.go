@@ -0,0 +1,175 @@
+package grading
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"case and spacing", "  Big   House ", "big house"},
+		{"punctuation", "well-known!", "well known"},
+		{"leading article", "The Cat", "cat"},
+		{"elided article", "l'amour", "amour"},
+		{"infinitive marker", "to be", "be"},
+		{"article alone is kept", "the", "the"},
+		{"article inside is kept", "cat the dog", "cat the dog"},
+		{"accents are kept", "Éclair", "éclair"},
+		{"decomposed accents are composed", "cafe\u0301", "café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldDiacritics(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"café", "cafe"},
+		{"über", "uber"},
+		{"niño", "nino"},
+		{"plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		if got := FoldDiacritics(tt.input); got != tt.want {
+			t.Errorf("FoldDiacritics(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{"both empty", "", "", 0},
+		{"empty input", "", "abc", 3},
+		{"empty expected", "abc", "", 3},
+		{"equal", "house", "house", 0},
+		{"substitution", "house", "mouse", 1},
+		{"insertion", "hose", "house", 1},
+		{"deletion", "houses", "house", 1},
+		{"transposition", "huose", "house", 1},
+		{"transposition at start", "ab", "ba", 1},
+		{"no edit across a transposition", "ca", "abc", 3},
+		{"several edits", "kitten", "sitting", 3},
+		{"accented runes", "héllo", "hello", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Distance(tt.a, tt.b); got != tt.want {
+				t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		want     []DiffSegment
+	}{
+		{"both empty", "", "", nil},
+		{"equal", "cat", "cat", []DiffSegment{{DiffEqual, "cat"}}},
+		{"empty input", "", "ab", []DiffSegment{{DiffInsert, "ab"}}},
+		{"empty expected", "ab", "", []DiffSegment{{DiffDelete, "ab"}}},
+		{"missing letter", "ct", "cat", []DiffSegment{{DiffEqual, "c"}, {DiffInsert, "a"}, {DiffEqual, "t"}}},
+		{"extra letter", "hause", "haus", []DiffSegment{{DiffEqual, "haus"}, {DiffDelete, "e"}}},
+		{"wrong accent", "cafe", "café", []DiffSegment{{DiffEqual, "caf"}, {DiffDelete, "e"}, {DiffInsert, "é"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.input, tt.expected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff(%q, %q) = %v, want %v", tt.input, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		expected     string
+		tolerance    Tolerance
+		wantGrade    Grade
+		wantDistance int
+	}{
+		{"exact", "house", "house", ToleranceStrict, GradeCorrect, 0},
+		{"case, punctuation and article", "The House!", "house", ToleranceStrict, GradeCorrect, 0},
+		{"missing accent strict", "cafe", "café", ToleranceStrict, GradeWrong, 1},
+		{"missing accent normal", "cafe", "café", ToleranceNormal, GradeAlmost, 1},
+		{"typo strict", "huose", "house", ToleranceStrict, GradeWrong, 1},
+		{"transposition normal", "huose", "house", ToleranceNormal, GradeAlmost, 1},
+		{"typo in short word normal", "car", "cat", ToleranceNormal, GradeWrong, 1},
+		{"typo in short word lenient", "car", "cat", ToleranceLenient, GradeAlmost, 1},
+		{"at the almost threshold", "elefant", "elephant", ToleranceNormal, GradeAlmost, 2},
+		{"past the almost threshold", "elefan", "elephant", ToleranceNormal, GradeWrong, 3},
+		{"three typos normal", "hipopotms", "hippopotamus", ToleranceNormal, GradeWrong, 3},
+		{"three typos lenient", "hipopotms", "hippopotamus", ToleranceLenient, GradeAlmost, 3},
+		{"accents and typo", "elefánt", "elephant", ToleranceNormal, GradeAlmost, 2},
+		{"invalid tolerance uses default", "huose", "house", Tolerance("unknown"), GradeAlmost, 1},
+		{"empty input", "", "house", ToleranceLenient, GradeWrong, 5},
+		{"blank input", "  ?! ", "house", ToleranceLenient, GradeWrong, 5},
+		{"empty expected", "house", "", ToleranceLenient, GradeWrong, 5},
+		{"both empty", "", "", ToleranceLenient, GradeWrong, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Evaluate(tt.input, tt.expected, tt.tolerance)
+			if got.Grade != tt.wantGrade {
+				t.Errorf("Evaluate(%q, %q, %s) grade = %s, want %s", tt.input, tt.expected, tt.tolerance, got.Grade, tt.wantGrade)
+			}
+			if got.Distance != tt.wantDistance {
+				t.Errorf("Evaluate(%q, %q, %s) distance = %d, want %d", tt.input, tt.expected, tt.tolerance, got.Distance, tt.wantDistance)
+			}
+			if got.Passed() != (tt.wantGrade != GradeWrong) {
+				t.Errorf("Evaluate(%q, %q, %s) passed = %v", tt.input, tt.expected, tt.tolerance, got.Passed())
+			}
+			if got.Grade == GradeCorrect && got.Diff != nil {
+				t.Errorf("Evaluate(%q, %q, %s) diff = %v, want none for a correct answer", tt.input, tt.expected, tt.tolerance, got.Diff)
+			}
+		})
+	}
+}
+
+func TestEvaluateLongInput(t *testing.T) {
+	input := strings.Repeat("a", MaxInputLength+1)
+
+	got := Evaluate(input, "a", ToleranceLenient)
+	if got.Grade != GradeWrong {
+		t.Errorf("grade = %s, want %s", got.Grade, GradeWrong)
+	}
+	if got.Distance != MaxInputLength+1 {
+		t.Errorf("distance = %d, want %d", got.Distance, MaxInputLength+1)
+	}
+	if got.Diff != nil {
+		t.Errorf("diff = %v, want none for an input over MaxInputLength", got.Diff)
+	}
+
+	// Inputs up to the limit are still compared
+	if got := Evaluate(strings.Repeat("a", MaxInputLength), strings.Repeat("a", MaxInputLength), ToleranceStrict); got.Grade != GradeCorrect {
+		t.Errorf("grade at MaxInputLength = %s, want %s", got.Grade, GradeCorrect)
+	}
+}
@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes every email to its own .eml file in a directory instead of sending it
+type FileMailer struct {
+	dir  string
+	from *mail.Address
+}
+
+// NewFileMailer creates a file mailer writing to dir. The directory is created on first use.
+func NewFileMailer(dir string, from *mail.Address) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send implements Mailer
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	to, err := parseRecipient(msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := now.UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix) + ".eml"
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, format(m.from, to, msg, now), 0o644); err != nil {
+		return err
+	}
+
+	log.Printf("Wrote email %q to %s", msg.Subject, path)
+	return nil
+}
+
+// LogMailer writes emails to the standard logger instead of sending them
+type LogMailer struct {
+	from *mail.Address
+}
+
+// NewLogMailer creates a log mailer
+func NewLogMailer(from *mail.Address) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+// Send implements Mailer
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	to, err := parseRecipient(msg)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Email to %s:\n%s", to.Address, format(m.from, to, msg, time.Now()))
+	return nil
+}
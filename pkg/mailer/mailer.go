@@ -0,0 +1,92 @@
+// Package mailer delivers plain-text emails.
+//
+// The SMTP mailer sends through a mail server; the file and log mailers keep emails
+// on the local machine for development and tests.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"vocabulary-app-be/pkg/config"
+)
+
+var (
+	ErrDriverRequired = errors.New("mail driver required")
+	ErrUnknownDriver  = errors.New("unknown mail driver")
+	ErrInvalidAddress = errors.New("invalid email address")
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New creates the mailer selected by cfg.MailDriver. Production requires the SMTP driver,
+// as the file and log drivers would never deliver password reset and verification emails.
+func New(cfg *config.Config) (Mailer, error) {
+	from, err := mail.ParseAddress(cfg.MailFrom)
+	if err != nil {
+		return nil, fmt.Errorf("%w: MAIL_FROM %q", ErrInvalidAddress, cfg.MailFrom)
+	}
+
+	if cfg.Environment == "production" && cfg.MailDriver != "smtp" {
+		return nil, fmt.Errorf("%w: MAIL_DRIVER must be smtp in production", ErrDriverRequired)
+	}
+	if cfg.MailDriver == "" {
+		return nil, fmt.Errorf("%w: MAIL_DRIVER is only optional in development", ErrDriverRequired)
+	}
+
+	switch cfg.MailDriver {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, from), nil
+	case "file":
+		return NewFileMailer(cfg.MailDir, from), nil
+	case "log":
+		return NewLogMailer(from), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDriver, cfg.MailDriver)
+	}
+}
+
+// format renders msg as an RFC 5322 message sent from the given address
+func format(from *mail.Address, to *mail.Address, msg *Message, date time.Time) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from.String() + "\r\n")
+	buf.WriteString("To: " + to.String() + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	buf.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteString("\r\n")
+	}
+	return buf.Bytes()
+}
+
+// parseRecipient parses the To address of msg
+func parseRecipient(msg *Message) (*mail.Address, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, msg.To)
+	}
+	return to, nil
+}
@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+
+	"vocabulary-app-be/pkg/config"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		driver      string
+		wantErr     error
+	}{
+		{"log in development", "development", "log", nil},
+		{"file in development", "development", "file", nil},
+		{"smtp in production", "production", "smtp", nil},
+		{"log in production", "production", "log", ErrDriverRequired},
+		{"file in production", "production", "file", ErrDriverRequired},
+		{"missing in production", "production", "", ErrDriverRequired},
+		{"missing in staging", "staging", "", ErrDriverRequired},
+		{"unknown", "development", "pigeon", ErrUnknownDriver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Environment: tt.environment,
+				MailDriver:  tt.driver,
+				MailFrom:    "Vocabulary App <no-reply@localhost>",
+				SMTPHost:    "localhost",
+				SMTPPort:    587,
+			}
+			if _, err := New(cfg); !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
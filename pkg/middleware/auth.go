@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,12 +11,30 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthMiddleware validates JWT tokens from cookies or Bearer tokens and sets user context
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// SessionAccess is what the login session of a token is allowed to do
+type SessionAccess int
+
+const (
+	// AccessRevoked rejects the token: its session was revoked or has expired
+	AccessRevoked SessionAccess = iota
+	// AccessUnverified rejects the token: the email of the user is not verified
+	AccessUnverified
+	// AccessReadOnly only allows safe methods (GET, HEAD, OPTIONS) until the email is verified
+	AccessReadOnly
+	// AccessFull allows every request
+	AccessFull
+)
+
+// SessionChecker reports what the login session of a token is allowed to do
+type SessionChecker interface {
+	CheckSession(ctx context.Context, userID, sessionID string) (SessionAccess, error)
+}
+
+// AuthMiddleware validates JWT tokens from cookies or Bearer tokens and sets user context.
+// Tokens whose session is no longer active are rejected, and so are requests the session may not make.
+func AuthMiddleware(jwtSecret string, sessions SessionChecker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var token string
-		var userID string
-		var err error
 
 		// Try to get token from HTTP-only cookie first (for web browsers)
 		token, cookieErr := ctx.Cookie("auth_token")
@@ -37,28 +56,55 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		// Validate token and extract user ID
-		userID, err = validateToken(token, jwtSecret)
-		if err != nil {
+		// Validate token and extract its claims
+		claims, err := validateToken(token, jwtSecret)
+		if err != nil || claims.SessionID == "" {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
-		// Set user ID in context
-		ctx.Set("userID", userID)
+		access, err := sessions.CheckSession(ctx.Request.Context(), claims.UserID, claims.SessionID)
+		if err != nil {
+			ctx.Error(err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		switch access {
+		case AccessRevoked:
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			return
+		case AccessUnverified:
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
+			return
+		case AccessReadOnly:
+			if !isSafeMethod(ctx.Request.Method) {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Verify your email address to make changes"})
+				return
+			}
+		}
+
+		// Set user and session IDs in context
+		ctx.Set("userID", claims.UserID)
+		ctx.Set("sessionID", claims.SessionID)
 		ctx.Next()
 	}
 }
 
+// isSafeMethod reports whether an HTTP method only reads data
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 // CustomClaims represents JWT custom claims
 type CustomClaims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// validateToken validates a JWT token and returns the user ID
-func validateToken(tokenString string, secret string) (string, error) {
+// validateToken validates a JWT token and returns its claims
+func validateToken(tokenString string, secret string) (*CustomClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (any, error) {
 		// Verify signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -68,26 +114,27 @@ func validateToken(tokenString string, secret string) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Extract claims
 	claims, ok := token.Claims.(*CustomClaims)
 	if !ok || !token.Valid {
-		return "", fmt.Errorf("invalid token claims")
+		return nil, fmt.Errorf("invalid token claims")
 	}
 
-	return claims.UserID, nil
+	return claims, nil
 }
 
-// GenerateToken generates a JWT token for a user
-func GenerateToken(userID string, email string, secret string, expirationHours int) (string, error) {
+// GenerateToken generates a JWT token for a login session of a user that expires after ttl
+func GenerateToken(userID string, email string, sessionID string, secret string, ttl time.Duration) (string, error) {
 	claims := CustomClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			// You would set this to a real expiration time in production
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expirationHours))),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
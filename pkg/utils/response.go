@@ -28,3 +28,12 @@ func ErrorResponse(ctx *gin.Context, statusCode int, message string) {
 		Error:   message,
 	})
 }
+
+// ErrorResponseWithData sends an error response with details in data
+func ErrorResponseWithData(ctx *gin.Context, statusCode int, message string, data any) {
+	ctx.JSON(statusCode, Response{
+		Success: false,
+		Error:   message,
+		Data:    data,
+	})
+}
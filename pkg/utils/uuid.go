@@ -0,0 +1,24 @@
+package utils
+
+// IsUUID reports whether s is a UUID in its canonical hyphenated form, so it can be bound
+// to a UUID column without Postgres rejecting the query
+func IsUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
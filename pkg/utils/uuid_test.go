@@ -0,0 +1,25 @@
+package utils
+
+import "testing"
+
+func TestIsUUID(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "3f2b8c4e-1a2d-4e5f-9a8b-7c6d5e4f3a2b", want: true},
+		{input: "3F2B8C4E-1A2D-4E5F-9A8B-7C6D5E4F3A2B", want: true},
+		{input: "", want: false},
+		{input: "not-a-uuid", want: false},
+		{input: "3f2b8c4e1a2d4e5f9a8b7c6d5e4f3a2b", want: false},
+		{input: "3f2b8c4e-1a2d-4e5f-9a8b-7c6d5e4f3a2g", want: false},
+		{input: "3f2b8c4e-1a2d-4e5f-9a8b_7c6d5e4f3a2b", want: false},
+		{input: "{3f2b8c4e-1a2d-4e5f-9a8b-7c6d5e4f3a2}", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsUUID(tt.input); got != tt.want {
+			t.Errorf("IsUUID(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}